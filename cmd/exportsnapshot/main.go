@@ -0,0 +1,83 @@
+// Command exportsnapshot writes the data lookups read — jurisdictions,
+// active rates and ZIP mappings — from Postgres to a snapshot file the
+// server can run from with SNAPSHOT_FILE, without a database. It reads
+// DATABASE_URL.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/prashkn/sales-tax-api/internal/store"
+)
+
+func main() {
+	out := flag.String("o", "snapshot.json.gz", "output file")
+	flag.Parse()
+
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		slog.Error("DATABASE_URL is required")
+		os.Exit(1)
+	}
+
+	if err := run(context.Background(), databaseURL, *out); err != nil {
+		slog.Error("snapshot export failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// run exports the snapshot. It's separate from main so its deferred
+// cleanup, including removing a partly written file, runs on failure.
+func run(ctx context.Context, databaseURL, out string) error {
+	db, err := store.New(ctx, databaseURL, store.Options{})
+	if err != nil {
+		return fmt.Errorf("connecting to database: %w", err)
+	}
+	defer db.Close()
+
+	start := time.Now()
+	snap, err := db.Snapshot(ctx)
+	if err != nil {
+		return fmt.Errorf("exporting snapshot: %w", err)
+	}
+
+	// Write beside the target and rename, so a server never loads a
+	// partial file.
+	tmp, err := os.CreateTemp(filepath.Dir(out), ".snapshot-*")
+	if err != nil {
+		return fmt.Errorf("creating snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := store.WriteSnapshot(tmp, snap); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	// CreateTemp makes the file 0600; a server running as another user
+	// must be able to read it.
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("setting snapshot file mode: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), out); err != nil {
+		return fmt.Errorf("renaming snapshot file: %w", err)
+	}
+
+	slog.Info("snapshot exported",
+		"file", out,
+		"version", snap.DatasetVersion,
+		"jurisdictions", len(snap.Jurisdictions),
+		"rates", len(snap.Rates),
+		"zips", len(snap.ZIPs),
+		"zip4_ranges", len(snap.ZIP4s),
+		"elapsed", time.Since(start).Round(time.Millisecond),
+	)
+	return nil
+}
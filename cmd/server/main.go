@@ -13,12 +13,10 @@ import (
 	chimw "github.com/go-chi/chi/v5/middleware"
 
 	"github.com/prashkn/sales-tax-api/internal/apikey"
-	"github.com/prashkn/sales-tax-api/internal/cache"
+	"github.com/prashkn/sales-tax-api/internal/app"
 	"github.com/prashkn/sales-tax-api/internal/config"
-	"github.com/prashkn/sales-tax-api/internal/geocoder"
 	"github.com/prashkn/sales-tax-api/internal/handler"
 	"github.com/prashkn/sales-tax-api/internal/service"
-	"github.com/prashkn/sales-tax-api/internal/store"
 )
 
 func main() {
@@ -31,31 +29,57 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Database
-	db, err := store.New(ctx, cfg.DatabaseURL)
+	// Data: Postgres, or a read-only snapshot when SNAPSHOT_FILE is set.
+	db, err := app.OpenStore(ctx, cfg)
 	if err != nil {
-		slog.Error("failed to connect to database", "error", err)
+		slog.Error("failed to open data store", "error", err)
 		os.Exit(1)
 	}
 	defer db.Close()
 
 	// Cache
-	rdb, err := cache.New(cfg.RedisURL, cfg.CacheTTLHrs)
+	rdb, err := app.OpenCache(cfg)
 	if err != nil {
-		slog.Error("failed to connect to redis", "error", err)
+		slog.Error("failed to configure cache", "error", err)
 		os.Exit(1)
 	}
 	defer rdb.Close()
+	slog.Info("cache configured", "backend", rdb.Backend())
+
+	// Cached responses are namespaced by dataset version; switch when a
+	// promotion lands and sweep out older namespaces.
+	if version, err := db.GetDatasetVersion(ctx); err != nil {
+		slog.Warn("failed to read dataset version", "error", err)
+	} else {
+		rdb.SetVersion(version)
+	}
+	go rdb.PurgeStale(ctx)
+	go rdb.ListenInvalidations(ctx, db.GetDatasetVersion)
+	go rdb.WatchVersion(ctx, time.Duration(cfg.CacheVersionPollS)*time.Second, db.GetDatasetVersion)
 
-	// Geocoder
-	gc := geocoder.NewClient()
+	// Geocoders
+	gc, rv, censusBreaker, err := app.Geocoders(cfg)
+	if err != nil {
+		slog.Error("failed to configure geocoder", "error", err)
+		os.Exit(1)
+	}
 
 	// Services
-	taxService := service.NewTaxService(db, rdb, gc)
+	taxService := service.NewTaxService(db, rdb, gc, rv)
+	if cfg.CacheWarmOnStart {
+		go func() {
+			p, err := taxService.WarmCache(ctx, 500, nil)
+			if err != nil {
+				slog.Error("cache warm-up failed", "error", err, "done", p.Done)
+				return
+			}
+			slog.Info("cache warm-up complete", "zips", p.Done, "cached", p.Cached)
+		}()
+	}
 
 	// Handlers
 	taxHandler := handler.NewTaxHandler(taxService)
-	healthHandler := handler.NewHealthHandler(db, rdb, taxService)
+	healthHandler := handler.NewHealthHandler(db, rdb, taxService, censusBreaker)
 	keyValidator := apikey.NewValidator(cfg.APIKeySecret)
 
 	// Router
@@ -74,7 +98,9 @@ func main() {
 
 		r.Get("/v1/tax/zip/{zip_code}", taxHandler.LookupByZIP)
 		r.Get("/v1/tax/address", taxHandler.LookupByAddress)
+		r.Get("/v1/tax/point", taxHandler.LookupByPoint)
 		r.Post("/v1/tax/calculate", taxHandler.Calculate)
+		r.Post("/v1/tax/use-tax", taxHandler.UseTax)
 		r.Post("/v1/tax/bulk", taxHandler.Bulk)
 	})
 
@@ -108,4 +134,4 @@ func main() {
 		slog.Error("server error", "error", err)
 		os.Exit(1)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,76 @@
+// Command warmcache precomputes the ZIP lookup response for every ZIP with
+// active mappings and writes them to Redis, so the first requests after a
+// deploy, Redis flush or data load don't all miss. It reads the same
+// environment as the server, including SNAPSHOT_FILE.
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/prashkn/sales-tax-api/internal/app"
+	"github.com/prashkn/sales-tax-api/internal/config"
+	"github.com/prashkn/sales-tax-api/internal/service"
+)
+
+func main() {
+	batchSize := flag.Int("batch", 500, "ZIPs per batch")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("failed to load config", "error", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+
+	db, err := app.OpenStore(ctx, cfg)
+	if err != nil {
+		slog.Error("failed to open data store", "error", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	// An in-process cache would vanish with this command.
+	if cfg.CacheBackend != "redis" {
+		slog.Error("cache warm-up requires CACHE_BACKEND=redis; use CACHE_WARM_ON_START with the memory backend")
+		os.Exit(1)
+	}
+	rdb, err := app.OpenCache(cfg)
+	if err != nil {
+		slog.Error("failed to configure cache", "error", err)
+		os.Exit(1)
+	}
+	defer rdb.Close()
+
+	// Warm-up only resolves ZIPs, but the service is built exactly as the
+	// server builds it.
+	gc, rv, _, err := app.Geocoders(cfg)
+	if err != nil {
+		slog.Error("failed to configure geocoder", "error", err)
+		os.Exit(1)
+	}
+
+	// Write under the version the servers are reading.
+	version, err := db.GetDatasetVersion(ctx)
+	if err != nil {
+		slog.Error("failed to read dataset version", "error", err)
+		os.Exit(1)
+	}
+	rdb.SetVersion(version)
+
+	start := time.Now()
+	ts := service.NewTaxService(db, rdb, gc, rv)
+	p, err := ts.WarmCache(ctx, *batchSize, func(p service.WarmProgress) {
+		slog.Info("warming cache", "done", p.Done, "total", p.Total, "cached", p.Cached)
+	})
+	if err != nil {
+		slog.Error("cache warm-up failed", "error", err, "done", p.Done)
+		os.Exit(1)
+	}
+	slog.Info("cache warm-up complete", "version", version, "zips", p.Done, "cached", p.Cached, "elapsed", time.Since(start).Round(time.Millisecond))
+}
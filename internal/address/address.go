@@ -0,0 +1,165 @@
+// Package address standardizes US street addresses to USPS Publication 28
+// conventions before geocoding and caching, so equivalent spellings of the
+// same address share a geocoder result and cache entry.
+package address
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Address is a standardized US address.
+type Address struct {
+	Street string `json:"street,omitempty"`
+	City   string `json:"city,omitempty"`
+	State  string `json:"state,omitempty"`
+	ZIP    string `json:"zip"`
+}
+
+// Normalize standardizes each part of an address: upper case, collapsed
+// whitespace, no periods or commas, USPS street suffix, directional and
+// unit designator abbreviations, two-letter state codes, and a "12345" or
+// "12345-6789" ZIP. Parts it doesn't recognize are kept as given, upper
+// cased.
+func Normalize(street, city, state, zip string) Address {
+	return Address{
+		Street: NormalizeStreet(street),
+		City:   NormalizeCity(city),
+		State:  NormalizeState(state),
+		ZIP:    NormalizeZIP(zip),
+	}
+}
+
+// NormalizeStreet standardizes a street line such as
+// "123 north Main Street, Apartment 4b" to "123 N MAIN ST APT 4B".
+func NormalizeStreet(street string) string {
+	tokens := tokenize(street)
+	if len(tokens) == 0 {
+		return ""
+	}
+
+	// Everything from the first unit designator on is the secondary unit.
+	// The search starts after the house number and first name word so
+	// streets like "1 Suite Ln" keep their name, and a designator must be
+	// followed by its identifier unless it is a bare "#4B".
+	split := len(tokens)
+	for i := 2; i < len(tokens); i++ {
+		if strings.HasPrefix(tokens[i], "#") || (isUnit(tokens[i]) && i+1 < len(tokens)) {
+			split = i
+			break
+		}
+	}
+	line, unit := tokens[:split], tokens[split:]
+
+	// Pre-directional directly after the house number, post-directional at
+	// the end; the street suffix is the last word before a post-directional.
+	// A directional followed only by a suffix is the street name itself, as
+	// in "77 North St".
+	_, nameIsSuffix := streetSuffixes[line[len(line)-1]]
+	if len(line) > 3 || (len(line) == 3 && !nameIsSuffix) {
+		if abbr, ok := directionals[line[1]]; ok && line[0][0] >= '0' && line[0][0] <= '9' {
+			line[1] = abbr
+		}
+	}
+	last := len(line) - 1
+	if last > 1 {
+		if abbr, ok := directionals[line[last]]; ok {
+			line[last] = abbr
+			last--
+		}
+	}
+	if last > 0 {
+		if abbr, ok := streetSuffixes[line[last]]; ok {
+			line[last] = abbr
+		}
+	}
+
+	if len(unit) > 0 {
+		unit = normalizeUnit(unit)
+	}
+	return strings.Join(append(line, unit...), " ")
+}
+
+// NormalizeCity upper-cases a city name and strips punctuation.
+func NormalizeCity(city string) string {
+	return strings.Join(tokenize(city), " ")
+}
+
+// NormalizeState returns the two-letter USPS code for a state name or code.
+// Unrecognized input is returned upper-cased.
+func NormalizeState(state string) string {
+	s := strings.Join(tokenize(state), " ")
+	if _, ok := stateCodes[s]; ok {
+		return s
+	}
+	if code, ok := stateNames[s]; ok {
+		return code
+	}
+	return s
+}
+
+// NormalizeZIP returns "12345" or "12345-6789" for 5 or 9 digit input,
+// ignoring spaces and hyphens. Anything else is returned trimmed.
+func NormalizeZIP(zip string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r == '-' || unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, zip)
+	if !isNumber(digits) {
+		return strings.TrimSpace(zip)
+	}
+	switch len(digits) {
+	case 5:
+		return digits
+	case 9:
+		return digits[:5] + "-" + digits[5:]
+	}
+	return strings.TrimSpace(zip)
+}
+
+// normalizeUnit abbreviates the unit designator and splits "#4B" into
+// "# 4B".
+func normalizeUnit(unit []string) []string {
+	first := unit[0]
+	if strings.HasPrefix(first, "#") && len(first) > 1 {
+		return append([]string{"#", first[1:]}, unit[1:]...)
+	}
+	if abbr, ok := unitDesignators[first]; ok {
+		unit[0] = abbr
+	}
+	// "APT #4" → "APT 4": the designator already says it's a unit.
+	if len(unit) > 1 && unit[0] != "#" && strings.HasPrefix(unit[1], "#") {
+		unit[1] = strings.TrimPrefix(unit[1], "#")
+		if unit[1] == "" {
+			unit = append(unit[:1], unit[2:]...)
+		}
+	}
+	return unit
+}
+
+// tokenize upper-cases s, drops periods and commas, and splits on
+// whitespace.
+func tokenize(s string) []string {
+	s = strings.ToUpper(s)
+	s = strings.NewReplacer(".", "", ",", " ").Replace(s)
+	return strings.Fields(s)
+}
+
+func isUnit(token string) bool {
+	_, ok := unitDesignators[token]
+	return ok
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
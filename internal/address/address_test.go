@@ -0,0 +1,73 @@
+package address
+
+import "testing"
+
+func TestNormalizeStreet(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"123 Main Street", "123 MAIN ST"},
+		{"  123   main   st. ", "123 MAIN ST"},
+		{"123 North Main Street", "123 N MAIN ST"},
+		{"500 Elm Avenue Southwest", "500 ELM AVE SW"},
+		{"9400 Wilshire Boulevard, Suite 200", "9400 WILSHIRE BLVD STE 200"},
+		{"10 Downing St Apartment 4b", "10 DOWNING ST APT 4B"},
+		{"10 Downing St #4b", "10 DOWNING ST # 4B"},
+		{"10 Downing St Apt #4b", "10 DOWNING ST APT 4B"},
+		{"1 Suite Lane", "1 SUITE LN"},     // designator as street name
+		{"77 North Street", "77 NORTH ST"}, // directional as street name
+		{"350 5th Ave", "350 5TH AVE"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := NormalizeStreet(tt.in); got != tt.want {
+				t.Errorf("NormalizeStreet(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeState(t *testing.T) {
+	tests := map[string]string{
+		"CA":                   "CA",
+		"ca":                   "CA",
+		"California":           "CA",
+		" new   york ":         "NY",
+		"District of Columbia": "DC",
+		"Narnia":               "NARNIA",
+	}
+
+	for in, want := range tests {
+		if got := NormalizeState(in); got != want {
+			t.Errorf("NormalizeState(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNormalizeZIP(t *testing.T) {
+	tests := map[string]string{
+		"90210":      "90210",
+		" 90210 ":    "90210",
+		"902101234":  "90210-1234",
+		"90210-1234": "90210-1234",
+		"90210 1234": "90210-1234",
+		"9021":       "9021",
+		"abcde":      "abcde",
+	}
+
+	for in, want := range tests {
+		if got := NormalizeZIP(in); got != want {
+			t.Errorf("NormalizeZIP(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	got := Normalize("123 main street", "st. louis", "missouri", "631011234")
+	want := Address{Street: "123 MAIN ST", City: "ST LOUIS", State: "MO", ZIP: "63101-1234"}
+	if got != want {
+		t.Fatalf("Normalize = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,175 @@
+package address
+
+// USPS Publication 28 abbreviations. Keys are upper case; each abbreviation
+// also maps to itself so already-standard input is recognized.
+
+// streetSuffixes covers the common street suffixes from Appendix C1.
+var streetSuffixes = withSelf(map[string]string{
+	"ALLEY":      "ALY",
+	"ANNEX":      "ANX",
+	"ARCADE":     "ARC",
+	"AVENUE":     "AVE",
+	"AV":         "AVE",
+	"BAYOU":      "BYU",
+	"BEACH":      "BCH",
+	"BEND":       "BND",
+	"BLUFF":      "BLF",
+	"BOULEVARD":  "BLVD",
+	"BRANCH":     "BR",
+	"BRIDGE":     "BRG",
+	"BROOK":      "BRK",
+	"BYPASS":     "BYP",
+	"CANYON":     "CYN",
+	"CAUSEWAY":   "CSWY",
+	"CENTER":     "CTR",
+	"CIRCLE":     "CIR",
+	"CLIFF":      "CLF",
+	"COMMON":     "CMN",
+	"CORNER":     "COR",
+	"COURSE":     "CRSE",
+	"COURT":      "CT",
+	"COVE":       "CV",
+	"CREEK":      "CRK",
+	"CRESCENT":   "CRES",
+	"CROSSING":   "XING",
+	"DRIVE":      "DR",
+	"ESTATE":     "EST",
+	"ESTATES":    "ESTS",
+	"EXPRESSWAY": "EXPY",
+	"EXTENSION":  "EXT",
+	"FERRY":      "FRY",
+	"FIELD":      "FLD",
+	"FIELDS":     "FLDS",
+	"FREEWAY":    "FWY",
+	"GARDEN":     "GDN",
+	"GARDENS":    "GDNS",
+	"GATEWAY":    "GTWY",
+	"GLEN":       "GLN",
+	"GREEN":      "GRN",
+	"GROVE":      "GRV",
+	"HARBOR":     "HBR",
+	"HEIGHTS":    "HTS",
+	"HIGHWAY":    "HWY",
+	"HILL":       "HL",
+	"HILLS":      "HLS",
+	"HOLLOW":     "HOLW",
+	"ISLAND":     "IS",
+	"JUNCTION":   "JCT",
+	"LAKE":       "LK",
+	"LAKES":      "LKS",
+	"LANDING":    "LNDG",
+	"LANE":       "LN",
+	"LOOP":       "LOOP",
+	"MALL":       "MALL",
+	"MANOR":      "MNR",
+	"MEADOW":     "MDW",
+	"MEADOWS":    "MDWS",
+	"MILL":       "ML",
+	"MOUNT":      "MT",
+	"MOUNTAIN":   "MTN",
+	"ORCHARD":    "ORCH",
+	"OVAL":       "OVAL",
+	"PARK":       "PARK",
+	"PARKWAY":    "PKWY",
+	"PASS":       "PASS",
+	"PATH":       "PATH",
+	"PIKE":       "PIKE",
+	"PINE":       "PNE",
+	"PINES":      "PNES",
+	"PLACE":      "PL",
+	"PLAIN":      "PLN",
+	"PLAZA":      "PLZ",
+	"POINT":      "PT",
+	"PORT":       "PRT",
+	"PRAIRIE":    "PR",
+	"RANCH":      "RNCH",
+	"RIDGE":      "RDG",
+	"RIVER":      "RIV",
+	"ROAD":       "RD",
+	"ROUTE":      "RTE",
+	"ROW":        "ROW",
+	"RUN":        "RUN",
+	"SHORE":      "SHR",
+	"SKYWAY":     "SKWY",
+	"SPRING":     "SPG",
+	"SPRINGS":    "SPGS",
+	"SQUARE":     "SQ",
+	"STATION":    "STA",
+	"STREET":     "ST",
+	"STR":        "ST",
+	"SUMMIT":     "SMT",
+	"TERRACE":    "TER",
+	"TRACE":      "TRCE",
+	"TRAIL":      "TRL",
+	"TURNPIKE":   "TPKE",
+	"VALLEY":     "VLY",
+	"VIEW":       "VW",
+	"VILLAGE":    "VLG",
+	"VISTA":      "VIS",
+	"WALK":       "WALK",
+	"WAY":        "WAY",
+})
+
+// directionals are the pre- and post-directionals.
+var directionals = withSelf(map[string]string{
+	"NORTH":     "N",
+	"SOUTH":     "S",
+	"EAST":      "E",
+	"WEST":      "W",
+	"NORTHEAST": "NE",
+	"NORTHWEST": "NW",
+	"SOUTHEAST": "SE",
+	"SOUTHWEST": "SW",
+})
+
+// unitDesignators are the secondary unit designators from Appendix C2 that
+// take an identifier ("APT 4B"). Designators without one, like REAR or
+// FRONT, are left alone since they collide with street names.
+var unitDesignators = withSelf(map[string]string{
+	"APARTMENT":  "APT",
+	"BUILDING":   "BLDG",
+	"DEPARTMENT": "DEPT",
+	"FLOOR":      "FL",
+	"HANGAR":     "HNGR",
+	"ROOM":       "RM",
+	"SPACE":      "SPC",
+	"SUITE":      "STE",
+	"TRAILER":    "TRLR",
+	"UNIT":       "UNIT",
+})
+
+// stateNames maps full state and territory names to USPS codes.
+var stateNames = map[string]string{
+	"ALABAMA": "AL", "ALASKA": "AK", "ARIZONA": "AZ", "ARKANSAS": "AR",
+	"CALIFORNIA": "CA", "COLORADO": "CO", "CONNECTICUT": "CT", "DELAWARE": "DE",
+	"DISTRICT OF COLUMBIA": "DC", "FLORIDA": "FL", "GEORGIA": "GA", "HAWAII": "HI",
+	"IDAHO": "ID", "ILLINOIS": "IL", "INDIANA": "IN", "IOWA": "IA",
+	"KANSAS": "KS", "KENTUCKY": "KY", "LOUISIANA": "LA", "MAINE": "ME",
+	"MARYLAND": "MD", "MASSACHUSETTS": "MA", "MICHIGAN": "MI", "MINNESOTA": "MN",
+	"MISSISSIPPI": "MS", "MISSOURI": "MO", "MONTANA": "MT", "NEBRASKA": "NE",
+	"NEVADA": "NV", "NEW HAMPSHIRE": "NH", "NEW JERSEY": "NJ", "NEW MEXICO": "NM",
+	"NEW YORK": "NY", "NORTH CAROLINA": "NC", "NORTH DAKOTA": "ND", "OHIO": "OH",
+	"OKLAHOMA": "OK", "OREGON": "OR", "PENNSYLVANIA": "PA", "RHODE ISLAND": "RI",
+	"SOUTH CAROLINA": "SC", "SOUTH DAKOTA": "SD", "TENNESSEE": "TN", "TEXAS": "TX",
+	"UTAH": "UT", "VERMONT": "VT", "VIRGINIA": "VA", "WASHINGTON": "WA",
+	"WEST VIRGINIA": "WV", "WISCONSIN": "WI", "WYOMING": "WY",
+	"PUERTO RICO": "PR", "GUAM": "GU", "VIRGIN ISLANDS": "VI",
+	"AMERICAN SAMOA": "AS", "NORTHERN MARIANA ISLANDS": "MP",
+}
+
+// stateCodes is the set of valid two-letter codes.
+var stateCodes = func() map[string]struct{} {
+	codes := make(map[string]struct{}, len(stateNames))
+	for _, code := range stateNames {
+		codes[code] = struct{}{}
+	}
+	return codes
+}()
+
+// withSelf adds each abbreviation as a key mapping to itself.
+func withSelf(m map[string]string) map[string]string {
+	for _, abbr := range m {
+		m[abbr] = abbr
+	}
+	return m
+}
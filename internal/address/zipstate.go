@@ -0,0 +1,80 @@
+package address
+
+import "strconv"
+
+// zip3Range assigns an inclusive range of 3-digit ZIP prefixes to a state.
+type zip3Range struct {
+	low, high int
+	state     string
+}
+
+// zip3States is the USPS assignment of 3-digit ZIP prefixes to states.
+// Single-prefix exceptions (IRS and government facilities) come before the
+// ranges they sit in; the first match wins. Military prefixes (AA, AE, AP)
+// and the shared Pacific territory prefix 969 are omitted, so their ZIPs
+// have no reference state.
+var zip3States = []zip3Range{
+	{5, 5, "NY"}, {55, 55, "MA"}, {201, 201, "VA"}, {569, 569, "DC"},
+	{733, 733, "TX"}, {885, 885, "TX"},
+
+	{6, 7, "PR"}, {8, 8, "VI"}, {9, 9, "PR"},
+	{10, 27, "MA"}, {28, 29, "RI"}, {30, 38, "NH"}, {39, 49, "ME"},
+	{50, 59, "VT"}, {60, 69, "CT"}, {70, 89, "NJ"},
+	{100, 149, "NY"}, {150, 196, "PA"}, {197, 199, "DE"}, {200, 205, "DC"},
+	{206, 219, "MD"}, {220, 246, "VA"}, {247, 268, "WV"}, {270, 289, "NC"},
+	{290, 299, "SC"}, {300, 319, "GA"}, {320, 339, "FL"}, {341, 349, "FL"},
+	{350, 369, "AL"}, {370, 385, "TN"}, {386, 397, "MS"}, {398, 399, "GA"},
+	{400, 427, "KY"}, {430, 459, "OH"}, {460, 479, "IN"}, {480, 499, "MI"},
+	{500, 528, "IA"}, {530, 549, "WI"}, {550, 567, "MN"}, {570, 577, "SD"},
+	{580, 588, "ND"}, {590, 599, "MT"}, {600, 629, "IL"}, {630, 658, "MO"},
+	{660, 679, "KS"}, {680, 693, "NE"}, {700, 715, "LA"}, {716, 729, "AR"},
+	{730, 749, "OK"}, {750, 799, "TX"}, {800, 816, "CO"}, {820, 831, "WY"},
+	{832, 838, "ID"}, {840, 847, "UT"}, {850, 865, "AZ"}, {870, 884, "NM"},
+	{889, 898, "NV"}, {900, 961, "CA"}, {967, 968, "HI"}, {970, 979, "OR"},
+	{980, 994, "WA"}, {995, 999, "AK"},
+}
+
+// StateForZIP returns the USPS state code a ZIP's 3-digit prefix is
+// assigned to. A handful of ZIPs near state lines are delivered across the
+// line, so a mismatch is a strong hint rather than proof of a bad address.
+func StateForZIP(zip string) (string, bool) {
+	if len(zip) < 3 {
+		return "", false
+	}
+	prefix, err := strconv.Atoi(zip[:3])
+	if err != nil {
+		return "", false
+	}
+	for _, r := range zip3States {
+		if prefix >= r.low && prefix <= r.high {
+			return r.state, true
+		}
+	}
+	return "", false
+}
+
+// stateFIPS maps 2-digit state FIPS codes to USPS codes.
+var stateFIPS = map[string]string{
+	"01": "AL", "02": "AK", "04": "AZ", "05": "AR", "06": "CA", "08": "CO",
+	"09": "CT", "10": "DE", "11": "DC", "12": "FL", "13": "GA", "15": "HI",
+	"16": "ID", "17": "IL", "18": "IN", "19": "IA", "20": "KS", "21": "KY",
+	"22": "LA", "23": "ME", "24": "MD", "25": "MA", "26": "MI", "27": "MN",
+	"28": "MS", "29": "MO", "30": "MT", "31": "NE", "32": "NV", "33": "NH",
+	"34": "NJ", "35": "NM", "36": "NY", "37": "NC", "38": "ND", "39": "OH",
+	"40": "OK", "41": "OR", "42": "PA", "44": "RI", "45": "SC", "46": "SD",
+	"47": "TN", "48": "TX", "49": "UT", "50": "VT", "51": "VA", "53": "WA",
+	"54": "WV", "55": "WI", "56": "WY", "60": "AS", "66": "GU", "69": "MP",
+	"72": "PR", "78": "VI",
+}
+
+// StateForFIPS returns the USPS code for a 2-digit state FIPS code.
+func StateForFIPS(fips string) (string, bool) {
+	code, ok := stateFIPS[fips]
+	return code, ok
+}
+
+// IsStateCode reports whether s is a two-letter USPS state or territory code.
+func IsStateCode(s string) bool {
+	_, ok := stateCodes[s]
+	return ok
+}
@@ -0,0 +1,43 @@
+package address
+
+import "testing"
+
+func TestStateForZIP(t *testing.T) {
+	tests := []struct {
+		zip    string
+		want   string
+		wantOK bool
+	}{
+		{"90210", "CA", true},
+		{"10001", "NY", true},
+		{"10001-1234", "NY", true},
+		{"00501", "NY", true}, // Holtsville IRS, inside Puerto Rico's block
+		{"73301", "TX", true}, // Austin IRS, inside Oklahoma's range
+		{"88510", "TX", true}, // El Paso, inside New Mexico's range
+		{"20101", "VA", true}, // Dulles, inside DC's range
+		{"97201", "OR", true},
+		{"99501", "AK", true},
+		{"09001", "", false}, // military (AE)
+		{"96910", "", false}, // Guam, shared Pacific prefix
+		{"abc12", "", false},
+		{"1", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.zip, func(t *testing.T) {
+			got, ok := StateForZIP(tt.zip)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("StateForZIP(%q) = %q, %v, want %q, %v", tt.zip, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestStateForFIPS(t *testing.T) {
+	if got, ok := StateForFIPS("06"); !ok || got != "CA" {
+		t.Errorf("StateForFIPS(06) = %q, %v", got, ok)
+	}
+	if _, ok := StateForFIPS("03"); ok {
+		t.Error("expected unassigned FIPS 03 to be unknown")
+	}
+}
@@ -0,0 +1,145 @@
+// Package app builds the data store, cache and geocoders from config, so
+// the server and the one-off commands run against the same setup.
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/prashkn/sales-tax-api/internal/boundary"
+	"github.com/prashkn/sales-tax-api/internal/cache"
+	"github.com/prashkn/sales-tax-api/internal/config"
+	"github.com/prashkn/sales-tax-api/internal/geocoder"
+	"github.com/prashkn/sales-tax-api/internal/store"
+)
+
+// OpenStore loads the snapshot file if one is configured, otherwise
+// connects to Postgres.
+func OpenStore(ctx context.Context, cfg *config.Config) (store.Store, error) {
+	if cfg.SnapshotFile == "" {
+		db, err := store.New(ctx, cfg.DatabaseURL, store.Options{
+			ReplicaURLs:   cfg.ReplicaURLs,
+			MaxReplicaLag: time.Duration(cfg.ReplicaMaxLagS) * time.Second,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(cfg.ReplicaURLs) > 0 {
+			slog.Info("routing lookups to read replicas", "replicas", len(cfg.ReplicaURLs))
+		}
+		return db, nil
+	}
+	snap, err := store.LoadSnapshot(cfg.SnapshotFile)
+	if err != nil {
+		return nil, err
+	}
+	version, _ := snap.GetDatasetVersion(ctx)
+	slog.Info("serving from snapshot", "file", cfg.SnapshotFile, "version", version)
+	return snap, nil
+}
+
+// OpenCache builds the response cache on the configured backend. Redis is
+// optional: without it responses are cached in process, and a Redis outage
+// only turns lookups into misses.
+func OpenCache(cfg *config.Config) (*cache.Cache, error) {
+	opts := cache.Options{
+		TTL:            time.Duration(cfg.CacheTTLHrs) * time.Hour,
+		GeocodeHitTTL:  time.Duration(cfg.GeocodeHitTTLHrs) * time.Hour,
+		GeocodeMissTTL: time.Duration(cfg.GeocodeMissTTLMin) * time.Minute,
+		NegativeTTL:    time.Duration(cfg.NegativeTTLMin) * time.Minute,
+		StaleTTL:       time.Duration(cfg.CacheStaleTTLHrs) * time.Hour,
+		LocalEntries:   cfg.LocalCacheSize,
+		LocalTTL:       time.Duration(cfg.LocalCacheTTLS) * time.Second,
+	}
+	var backend cache.Backend
+	switch cfg.CacheBackend {
+	case "redis":
+		rb, err := cache.NewRedis(cfg.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("configuring redis: %w", err)
+		}
+		backend = rb
+	default:
+		backend = cache.NewMemory(cfg.MemoryCacheSize)
+		// The backend is already in process.
+		opts.LocalEntries = 0
+	}
+	return cache.New(backend, opts), nil
+}
+
+// Geocoders builds the address geocoder chain and the point reverser, and
+// returns the Census breaker both go through for health reporting.
+func Geocoders(cfg *config.Config) (geocoder.Geocoder, geocoder.Reverser, *geocoder.Breaker, error) {
+	// Census calls go through one breaker, so a slow Census API fails fast
+	// to ZIP fallback instead of holding up every address lookup.
+	censusBreaker := geocoder.NewBreaker("census", geocoder.Policy{
+		MaxAttempts:      1 + cfg.GeocodeRetries,
+		AttemptTimeout:   time.Duration(cfg.GeocodeAttemptMs) * time.Millisecond,
+		Budget:           time.Duration(cfg.GeocodeBudgetMs) * time.Millisecond,
+		Backoff:          100 * time.Millisecond,
+		FailureThreshold: cfg.BreakerFailures,
+		Cooldown:         time.Duration(cfg.BreakerCooldownS) * time.Second,
+	})
+	censusClient := geocoder.NewClient()
+	census := censusBreaker.Geocoder(censusClient)
+
+	// Coordinates → FIPS: local boundaries if configured, otherwise Census.
+	var rv geocoder.Reverser = censusBreaker.Reverser(censusClient)
+	if len(cfg.BoundaryFiles) > 0 {
+		bi, err := boundary.LoadFiles(cfg.BoundaryFiles...)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("loading boundary files: %w", err)
+		}
+		slog.Info("loaded boundaries", "files", len(cfg.BoundaryFiles), "features", bi.Len())
+		rv = bi
+	}
+
+	gc, err := buildGeocoder(cfg, census, rv)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return gc, rv, censusBreaker, nil
+}
+
+// buildGeocoder assembles the address geocoder chain from GEOCODER_CHAIN
+// entries of the form "name" or "name:timeout", e.g. "census:3s,nominatim:1s".
+// When local boundaries are loaded, FIPS codes for every provider's match
+// come from them.
+func buildGeocoder(cfg *config.Config, census geocoder.Geocoder, rv geocoder.Reverser) (geocoder.Geocoder, error) {
+	var providers []geocoder.Provider
+	for _, spec := range cfg.GeocoderChain {
+		name, timeoutStr, _ := strings.Cut(spec, ":")
+
+		var timeout time.Duration
+		if timeoutStr != "" {
+			d, err := time.ParseDuration(timeoutStr)
+			if err != nil {
+				return nil, fmt.Errorf("geocoder %q: invalid timeout: %w", name, err)
+			}
+			timeout = d
+		}
+
+		var g geocoder.Geocoder
+		switch name {
+		case "census":
+			g = census
+		case "nominatim":
+			if cfg.NominatimURL == "" {
+				return nil, fmt.Errorf("geocoder %q requires NOMINATIM_URL", name)
+			}
+			g = geocoder.NewNominatimClient(cfg.NominatimURL, rv)
+		default:
+			return nil, fmt.Errorf("unknown geocoder %q", name)
+		}
+		providers = append(providers, geocoder.Provider{Name: name, Geocoder: g, Timeout: timeout})
+	}
+
+	var gc geocoder.Geocoder = geocoder.NewChain(providers...)
+	if _, local := rv.(*boundary.Index); local {
+		gc = geocoder.WithBoundaries(gc, rv)
+	}
+	return gc, nil
+}
@@ -0,0 +1,149 @@
+package boundary
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadFiles builds an index from GeoJSON FeatureCollection files. TIGER/Line
+// shapefiles can be converted with:
+//
+//	ogr2ogr -f GeoJSON -t_srs EPSG:4326 tl_2024_us_county.geojson tl_2024_us_county.shp
+func LoadFiles(paths ...string) (*Index, error) {
+	idx := NewIndex()
+	for _, path := range paths {
+		if err := idx.LoadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	return idx, nil
+}
+
+// LoadFile adds every Polygon and MultiPolygon feature in a GeoJSON
+// FeatureCollection to the index.
+func (idx *Index) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening boundary file: %w", err)
+	}
+	defer f.Close()
+
+	var fc featureCollection
+	if err := json.NewDecoder(f).Decode(&fc); err != nil {
+		return fmt.Errorf("decoding boundary file %s: %w", path, err)
+	}
+
+	for _, gf := range fc.Features {
+		feature, ok, err := gf.toFeature()
+		if err != nil {
+			return fmt.Errorf("boundary file %s: %w", path, err)
+		}
+		if ok {
+			idx.Add(feature)
+		}
+	}
+	return nil
+}
+
+// toFeature converts a GeoJSON feature. ok is false for features that carry
+// no usable FIPS code, layer, or polygon geometry.
+func (gf geoJSONFeature) toFeature() (Feature, bool, error) {
+	fips := gf.property("GEOID", "geoid", "fips_code")
+	if fips == "" {
+		return Feature{}, false, nil
+	}
+
+	layer := strings.ToLower(gf.property("layer", "type"))
+	if layer == "" {
+		layer = layerForGEOID(fips)
+	}
+	if layer == "" {
+		return Feature{}, false, nil
+	}
+
+	var polys []polygon
+	switch gf.Geometry.Type {
+	case "Polygon":
+		var coords [][][]float64
+		if err := json.Unmarshal(gf.Geometry.Coordinates, &coords); err != nil {
+			return Feature{}, false, fmt.Errorf("feature %s: %w", fips, err)
+		}
+		polys = append(polys, toPolygon(coords))
+	case "MultiPolygon":
+		var coords [][][][]float64
+		if err := json.Unmarshal(gf.Geometry.Coordinates, &coords); err != nil {
+			return Feature{}, false, fmt.Errorf("feature %s: %w", fips, err)
+		}
+		for _, c := range coords {
+			polys = append(polys, toPolygon(c))
+		}
+	default:
+		return Feature{}, false, nil
+	}
+	// An empty outer ring has no bounds, and would otherwise be indexed
+	// into every cell.
+	for _, poly := range polys {
+		if len(poly) == 0 || len(poly[0]) == 0 {
+			return Feature{}, false, fmt.Errorf("feature %s: polygon has an empty outer ring", fips)
+		}
+	}
+
+	return Feature{FIPSCode: fips, Layer: layer, polygons: polys}, true, nil
+}
+
+// layerForGEOID infers the layer from TIGER GEOID lengths: 2-digit states,
+// 5-digit state+county, 7-digit state+place, 10-digit state+county+cousub.
+func layerForGEOID(geoid string) string {
+	switch len(geoid) {
+	case 2:
+		return LayerState
+	case 5:
+		return LayerCounty
+	case 7:
+		return LayerPlace
+	case 10:
+		return LayerCountySubdivision
+	}
+	return ""
+}
+
+func toPolygon(coords [][][]float64) polygon {
+	poly := make(polygon, 0, len(coords))
+	for _, rc := range coords {
+		r := make(ring, 0, len(rc))
+		for _, c := range rc {
+			if len(c) < 2 {
+				continue
+			}
+			r = append(r, point{lng: c[0], lat: c[1]})
+		}
+		poly = append(poly, r)
+	}
+	return poly
+}
+
+// GeoJSON types.
+
+type featureCollection struct {
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Properties map[string]any `json:"properties"`
+	Geometry   struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	} `json:"geometry"`
+}
+
+// property returns the first non-empty string property among keys.
+func (gf geoJSONFeature) property(keys ...string) string {
+	for _, k := range keys {
+		if v, ok := gf.Properties[k].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,213 @@
+// Package boundary resolves coordinates to FIPS codes locally from
+// jurisdiction polygons, so point lookups don't depend on the Census
+// geocoder being reachable.
+package boundary
+
+import (
+	"context"
+	"math"
+	"slices"
+
+	"github.com/prashkn/sales-tax-api/internal/geocoder"
+)
+
+// Layer names. A feature's layer decides which Result field it fills.
+const (
+	LayerState  = geocoder.LayerState
+	LayerCounty = geocoder.LayerCounty
+	LayerPlace  = geocoder.LayerPlace
+	// LayerCountySubdivision features carry 10-digit GEOIDs.
+	LayerCountySubdivision = geocoder.LayerCountySubdivision
+	// LayerTribalArea features must carry an explicit layer property.
+	LayerTribalArea = geocoder.LayerTribalArea
+	// LayerSpecialDistrict features have arbitrary FIPS codes, so they must
+	// carry an explicit layer property.
+	LayerSpecialDistrict = geocoder.LayerSpecialDistrict
+)
+
+// defaultCellSize is the grid cell edge in degrees. Half a degree keeps
+// county candidate lists short without exploding the cell count for states.
+const defaultCellSize = 0.5
+
+type point struct {
+	lng, lat float64
+}
+
+type bbox struct {
+	minLng, minLat, maxLng, maxLat float64
+}
+
+func (b bbox) contains(p point) bool {
+	return p.lng >= b.minLng && p.lng <= b.maxLng && p.lat >= b.minLat && p.lat <= b.maxLat
+}
+
+// ring is a closed sequence of vertices. The first ring of a polygon is its
+// outer boundary; any further rings are holes.
+type ring []point
+
+type polygon []ring
+
+// Feature is one jurisdiction boundary.
+type Feature struct {
+	FIPSCode string
+	Layer    string
+	polygons []polygon
+	bounds   bbox
+}
+
+type cellKey struct {
+	x, y int
+}
+
+// Index is an in-memory spatial index of jurisdiction polygons bucketed on a
+// fixed lat/lng grid. It is safe for concurrent reads once built.
+type Index struct {
+	cellSize float64
+	features []Feature
+	cells    map[cellKey][]int
+	layers   []string // in the order first added
+}
+
+func NewIndex() *Index {
+	return &Index{
+		cellSize: defaultCellSize,
+		cells:    make(map[cellKey][]int),
+	}
+}
+
+// Len returns the number of indexed features.
+func (idx *Index) Len() int {
+	return len(idx.features)
+}
+
+// Add indexes a feature. Features without any polygon are ignored.
+func (idx *Index) Add(f Feature) {
+	if len(f.polygons) == 0 {
+		return
+	}
+	f.bounds = boundsOf(f.polygons)
+
+	i := len(idx.features)
+	idx.features = append(idx.features, f)
+	if !slices.Contains(idx.layers, f.Layer) {
+		idx.layers = append(idx.layers, f.Layer)
+	}
+
+	minX, minY := idx.cellOf(point{f.bounds.minLng, f.bounds.minLat})
+	maxX, maxY := idx.cellOf(point{f.bounds.maxLng, f.bounds.maxLat})
+	for x := minX; x <= maxX; x++ {
+		for y := minY; y <= maxY; y++ {
+			k := cellKey{x, y}
+			idx.cells[k] = append(idx.cells[k], i)
+		}
+	}
+}
+
+// Lookup returns every feature containing the point, in the order they were
+// added.
+func (idx *Index) Lookup(lat, lng float64) []Feature {
+	p := point{lng: lng, lat: lat}
+	x, y := idx.cellOf(p)
+
+	var found []Feature
+	for _, i := range idx.cells[cellKey{x, y}] {
+		f := idx.features[i]
+		if !f.bounds.contains(p) {
+			continue
+		}
+		for _, poly := range f.polygons {
+			if poly.contains(p) {
+				found = append(found, f)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// Reverse resolves a latitude/longitude to FIPS codes from the indexed
+// polygons. It has the same contract as geocoder.Client.Reverse: nil (no
+// error) means no state polygon contains the point.
+func (idx *Index) Reverse(_ context.Context, lat, lng float64) (*geocoder.Result, error) {
+	result := &geocoder.Result{}
+	for _, f := range idx.Lookup(lat, lng) {
+		switch f.Layer {
+		case LayerState:
+			if result.StateFIPS == "" {
+				result.StateFIPS = f.FIPSCode
+			}
+		case LayerCounty:
+			if result.CountyFIPS == "" {
+				result.CountyFIPS = f.FIPSCode
+			}
+		case LayerPlace:
+			if result.PlaceFIPS == "" {
+				result.PlaceFIPS = f.FIPSCode
+			}
+		case LayerCountySubdivision:
+			if result.SubdivisionFIPS == "" {
+				result.SubdivisionFIPS = f.FIPSCode
+			}
+		case LayerTribalArea:
+			if result.TribalFIPS == "" {
+				result.TribalFIPS = f.FIPSCode
+			}
+		case LayerSpecialDistrict:
+			// Districts overlap, so every one containing the point applies.
+			result.SpecialDistricts = append(result.SpecialDistricts, f.FIPSCode)
+		}
+	}
+
+	// Every county and place GEOID starts with its state FIPS, so a county
+	// layer alone is enough to recover the state.
+	if result.StateFIPS == "" && len(result.CountyFIPS) >= 2 {
+		result.StateFIPS = result.CountyFIPS[:2]
+	}
+	if result.StateFIPS == "" {
+		return nil, nil
+	}
+	result.Latitude = lat
+	result.Longitude = lng
+	result.Layers = slices.Clone(idx.layers)
+	return result, nil
+}
+
+func (idx *Index) cellOf(p point) (int, int) {
+	return int(math.Floor(p.lng / idx.cellSize)), int(math.Floor(p.lat / idx.cellSize))
+}
+
+// contains reports whether p is inside the polygon using even-odd ray
+// casting across all rings, which treats holes correctly.
+func (poly polygon) contains(p point) bool {
+	inside := false
+	for _, r := range poly {
+		for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+			a, b := r[i], r[j]
+			if (a.lat > p.lat) != (b.lat > p.lat) &&
+				p.lng < (b.lng-a.lng)*(p.lat-a.lat)/(b.lat-a.lat)+a.lng {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+func boundsOf(polys []polygon) bbox {
+	b := bbox{
+		minLng: math.Inf(1), minLat: math.Inf(1),
+		maxLng: math.Inf(-1), maxLat: math.Inf(-1),
+	}
+	for _, poly := range polys {
+		if len(poly) == 0 {
+			continue
+		}
+		// Holes sit inside the outer ring, so it alone bounds the polygon.
+		for _, p := range poly[0] {
+			b.minLng = math.Min(b.minLng, p.lng)
+			b.minLat = math.Min(b.minLat, p.lat)
+			b.maxLng = math.Max(b.maxLng, p.lng)
+			b.maxLat = math.Max(b.maxLat, p.lat)
+		}
+	}
+	return b
+}
@@ -0,0 +1,170 @@
+package boundary
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// testCollection has a state covering (-120,30)-(-110,40), a county in its
+// lower-left quarter, and a place inside the county with a hole at its centre.
+const testCollection = `{
+  "type": "FeatureCollection",
+  "features": [
+    {"type": "Feature", "properties": {"GEOID": "06"},
+     "geometry": {"type": "Polygon", "coordinates": [[[-120,30],[-110,30],[-110,40],[-120,40],[-120,30]]]}},
+    {"type": "Feature", "properties": {"GEOID": "06037"},
+     "geometry": {"type": "Polygon", "coordinates": [[[-120,30],[-115,30],[-115,35],[-120,35],[-120,30]]]}},
+    {"type": "Feature", "properties": {"GEOID": "0644000"},
+     "geometry": {"type": "MultiPolygon", "coordinates": [[
+       [[-119,31],[-116,31],[-116,34],[-119,34],[-119,31]],
+       [[-118,32],[-117,32],[-117,33],[-118,33],[-118,32]]
+     ]]}},
+    {"type": "Feature", "properties": {"NAME": "no geoid"},
+     "geometry": {"type": "Polygon", "coordinates": [[[-120,30],[-110,30],[-110,40],[-120,30]]]}}
+  ]
+}`
+
+func loadTestIndex(t *testing.T) *Index {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "boundaries.geojson")
+	if err := os.WriteFile(path, []byte(testCollection), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	idx, err := LoadFiles(path)
+	if err != nil {
+		t.Fatalf("loading boundaries: %v", err)
+	}
+	return idx
+}
+
+func TestLoadFiles_SkipsFeaturesWithoutGEOID(t *testing.T) {
+	idx := loadTestIndex(t)
+	if idx.Len() != 3 {
+		t.Fatalf("expected 3 indexed features, got %d", idx.Len())
+	}
+}
+
+func TestReverse(t *testing.T) {
+	idx := loadTestIndex(t)
+
+	tests := []struct {
+		name                 string
+		lat, lng             float64
+		state, county, place string
+	}{
+		{"inside place", 31.5, -118.5, "06", "06037", "0644000"},
+		{"inside place hole", 32.5, -117.5, "06", "06037", ""},
+		{"county only", 34.5, -115.5, "06", "06037", ""},
+		{"state only", 38, -112, "06", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := idx.Reverse(context.Background(), tt.lat, tt.lng)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result == nil {
+				t.Fatal("expected non-nil result")
+			}
+			if result.StateFIPS != tt.state || result.CountyFIPS != tt.county || result.PlaceFIPS != tt.place {
+				t.Errorf("got (%q, %q, %q), want (%q, %q, %q)",
+					result.StateFIPS, result.CountyFIPS, result.PlaceFIPS, tt.state, tt.county, tt.place)
+			}
+		})
+	}
+}
+
+func TestReverse_ReportsLayers(t *testing.T) {
+	idx := loadTestIndex(t)
+
+	result, err := idx.Reverse(context.Background(), 38, -112)
+	if err != nil || result == nil {
+		t.Fatalf("Reverse = %+v, %v", result, err)
+	}
+	if !result.HasLayer(LayerPlace) || result.HasLayer(LayerSpecialDistrict) {
+		t.Errorf("Layers = %v, want the state, county and place layers", result.Layers)
+	}
+}
+
+func TestReverse_LayersAreCopied(t *testing.T) {
+	idx := loadTestIndex(t)
+
+	result, err := idx.Reverse(context.Background(), 38, -112)
+	if err != nil || result == nil {
+		t.Fatalf("Reverse = %+v, %v", result, err)
+	}
+	result.Layers[0] = "mutated"
+	if idx.layers[0] == "mutated" {
+		t.Error("Reverse returned the index's own layers slice")
+	}
+}
+
+func TestLoadFiles_RejectsEmptyRings(t *testing.T) {
+	for _, geometry := range []string{
+		`{"type": "Polygon", "coordinates": [[]]}`,
+		`{"type": "MultiPolygon", "coordinates": [[[[-120,30],[-110,30],[-110,40],[-120,30]]], []]}`,
+	} {
+		path := filepath.Join(t.TempDir(), "boundaries.geojson")
+		fc := `{"type": "FeatureCollection", "features": [{"type": "Feature", "properties": {"GEOID": "06"}, "geometry": ` + geometry + `}]}`
+		if err := os.WriteFile(path, []byte(fc), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadFiles(path); err == nil {
+			t.Errorf("expected error loading %s", geometry)
+		}
+	}
+}
+
+func TestReverse_OutsideAllPolygons(t *testing.T) {
+	idx := loadTestIndex(t)
+
+	result, err := idx.Reverse(context.Background(), 45, -100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != nil {
+		t.Fatalf("expected nil result outside all polygons, got %+v", result)
+	}
+}
+
+func TestLoadFiles_MissingFile(t *testing.T) {
+	if _, err := LoadFiles(filepath.Join(t.TempDir(), "missing.geojson")); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+func TestReverse_SpecialDistricts(t *testing.T) {
+	idx := loadTestIndex(t)
+	square := func(minLng, minLat, maxLng, maxLat float64) []polygon {
+		return []polygon{{ring{{minLng, minLat}, {maxLng, minLat}, {maxLng, maxLat}, {minLng, maxLat}, {minLng, minLat}}}}
+	}
+	// Two overlapping districts in the west of the county.
+	idx.Add(Feature{FIPSCode: "06037SD01", Layer: LayerSpecialDistrict, polygons: square(-120, 30, -117, 35)})
+	idx.Add(Feature{FIPSCode: "06037SD02", Layer: LayerSpecialDistrict, polygons: square(-120, 30, -118, 32)})
+
+	tests := []struct {
+		name      string
+		lat, lng  float64
+		districts []string
+	}{
+		{"both districts", 31, -119, []string{"06037SD01", "06037SD02"}},
+		{"one district", 34, -117.5, []string{"06037SD01"}},
+		{"county outside districts", 34.5, -115.5, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := idx.Reverse(context.Background(), tt.lat, tt.lng)
+			if err != nil || result == nil {
+				t.Fatalf("unexpected result %+v, %v", result, err)
+			}
+			if !slices.Equal(result.SpecialDistricts, tt.districts) {
+				t.Errorf("SpecialDistricts = %v, want %v", result.SpecialDistricts, tt.districts)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Backend stores encoded cache entries shared by every lookup. Redis
+// shares them across instances; Memory keeps them in the process for
+// deployments without Redis.
+type Backend interface {
+	// Get returns the value stored under key, or ErrMiss.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores value under key for ttl. A zero ttl means it doesn't
+	// expire.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetMany stores every entry for ttl, in as few round trips as the
+	// backend allows.
+	SetMany(ctx context.Context, entries map[string][]byte, ttl time.Duration) error
+	// DeletePrefix deletes keys starting with prefix, except those starting
+	// with keep, and returns how many it deleted.
+	DeletePrefix(ctx context.Context, prefix, keep string) (int, error)
+	// Publish sends message to other instances listening on channel.
+	Publish(ctx context.Context, channel, message string) error
+	// Subscribe calls handle with each message published on channel until
+	// ctx is canceled.
+	Subscribe(ctx context.Context, channel string, handle func(message string))
+	Ping(ctx context.Context) error
+	Close() error
+	// Name identifies the backend in health output.
+	Name() string
+}
+
+// ErrMiss is returned by Get when nothing is cached under the key.
+var ErrMiss = errors.New("cache miss")
@@ -0,0 +1,317 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prashkn/sales-tax-api/internal/store"
+)
+
+// backendRetry is how long the backend is skipped after an error, so an
+// outage doesn't add a timeout to every lookup.
+const backendRetry = 5 * time.Second
+
+type Cache struct {
+	backend     Backend
+	local       *lru // nil when the local tier is disabled
+	ttl         time.Duration
+	geoHitTTL   time.Duration
+	geoMissTTL  time.Duration
+	negativeTTL time.Duration
+	staleTTL    time.Duration
+	now         func() time.Time
+
+	// version is the dataset version rate responses are namespaced by.
+	// Geocoder results don't depend on rate data and aren't versioned.
+	version atomic.Pointer[string]
+
+	// downUntil is when to try the backend again after an error, in Unix
+	// nanoseconds; zero while it's healthy.
+	downUntil atomic.Int64
+
+	localStats, backendStats tierCounters
+}
+
+// Options configures cache TTLs and the in-process tier.
+type Options struct {
+	// TTL applies to rate responses.
+	TTL time.Duration
+	// GeocodeHitTTL and GeocodeMissTTL apply to geocoder results; misses
+	// are kept briefly so typos don't pin a miss for days.
+	GeocodeHitTTL  time.Duration
+	GeocodeMissTTL time.Duration
+	// NegativeTTL applies to ZIPs cached as having no jurisdictions.
+	NegativeTTL time.Duration
+	// StaleTTL is how long rate responses are kept past TTL, to be
+	// served when the database can't produce a fresh one.
+	StaleTTL time.Duration
+	// LocalEntries bounds the in-process tier in front of the backend;
+	// zero disables it, as it's redundant in front of Memory. LocalTTL caps
+	// how long an entry lives there, which bounds how stale one instance can
+	// be relative to the backend.
+	LocalEntries int
+	LocalTTL     time.Duration
+}
+
+// New returns a Cache storing entries in backend.
+func New(backend Backend, opts Options) *Cache {
+	c := &Cache{
+		backend:     backend,
+		ttl:         opts.TTL,
+		geoHitTTL:   opts.GeocodeHitTTL,
+		geoMissTTL:  opts.GeocodeMissTTL,
+		negativeTTL: opts.NegativeTTL,
+		staleTTL:    opts.StaleTTL,
+		now:         time.Now,
+	}
+	if opts.LocalEntries > 0 && opts.LocalTTL > 0 {
+		c.local = newLRU(opts.LocalEntries, opts.LocalTTL)
+	}
+	c.SetVersion(store.DatasetVersionNone)
+	return c
+}
+
+func (c *Cache) Close() error {
+	return c.backend.Close()
+}
+
+func (c *Cache) Ping(ctx context.Context) error {
+	if err := c.backend.Ping(ctx); err != nil {
+		return err
+	}
+	c.backendUpAgain()
+	return nil
+}
+
+// Backend returns the name of the backend, e.g. "redis".
+func (c *Cache) Backend() string {
+	return c.backend.Name()
+}
+
+func (c *Cache) Get(ctx context.Context, zipCode string, dest any) error {
+	return c.get(ctx, keyForZIP(c.PinnedVersion(ctx), zipCode), dest)
+}
+
+func (c *Cache) Set(ctx context.Context, zipCode string, value any) error {
+	return c.set(ctx, keyForZIP(c.PinnedVersion(ctx), zipCode), value, c.ttl, c.staleTTL)
+}
+
+// SetZIPs caches many ZIP responses in one backend round trip. It's for
+// bulk loads, so the local tier is left alone. Unlike the other writes, it
+// returns backend errors rather than skipping a backend that's down.
+func (c *Cache) SetZIPs(ctx context.Context, responses map[string]any) error {
+	version := c.PinnedVersion(ctx)
+	entries := make(map[string][]byte, len(responses))
+	for zip, value := range responses {
+		data, err := c.encode(value, c.ttl)
+		if err != nil {
+			return err
+		}
+		entries[keyForZIP(version, zip)] = data
+	}
+	return c.backend.SetMany(ctx, entries, c.ttl+c.staleTTL)
+}
+
+// GetAddress reads a cached address lookup response. addrKey comes from
+// AddressKey.
+func (c *Cache) GetAddress(ctx context.Context, addrKey string, dest any) error {
+	return c.get(ctx, keyForAddress(c.PinnedVersion(ctx), addrKey), dest)
+}
+
+func (c *Cache) SetAddress(ctx context.Context, addrKey string, value any) error {
+	return c.set(ctx, keyForAddress(c.PinnedVersion(ctx), addrKey), value, c.ttl, c.staleTTL)
+}
+
+// GetGeocode reads a cached geocoder result for an address key.
+func (c *Cache) GetGeocode(ctx context.Context, addrKey string, dest any) error {
+	return c.get(ctx, keyForGeocode(addrKey), dest)
+}
+
+// SetGeocode caches a geocoder result. matched selects the hit or miss TTL.
+func (c *Cache) SetGeocode(ctx context.Context, addrKey string, value any, matched bool) error {
+	ttl := c.geoHitTTL
+	if !matched {
+		ttl = c.geoMissTTL
+	}
+	return c.set(ctx, keyForGeocode(addrKey), value, ttl, 0)
+}
+
+// ErrNotFound is returned by Get when the ZIP is cached as having no
+// jurisdictions.
+var ErrNotFound = errors.New("cached as not found")
+
+// notFoundMarker is stored in place of a response for a ZIP with no
+// jurisdictions. It isn't valid JSON, so it can't collide with a response.
+var notFoundMarker = []byte("\x00notfound")
+
+// SetNotFound records that a ZIP has no jurisdictions, for the shorter
+// negative TTL, so repeated lookups for bad ZIPs skip the database.
+func (c *Cache) SetNotFound(ctx context.Context, zipCode string) error {
+	if c.negativeTTL <= 0 {
+		return nil
+	}
+	return c.setRaw(ctx, keyForZIP(c.PinnedVersion(ctx), zipCode), notFoundMarker, c.negativeTTL, c.negativeTTL)
+}
+
+// ErrStale is returned by Get and GetAddress, with dest filled in, when the
+// entry is past its TTL but still within the stale window. Callers should
+// refresh it, and may serve it if they can't.
+var ErrStale = errors.New("cached value is stale")
+
+// entry wraps every cached value with the time it turns stale, so one key
+// serves as both the fresh copy and the longer-lived stale one.
+type entry struct {
+	FreshUntil int64           `json:"fresh_until"` // Unix seconds
+	Value      json.RawMessage `json:"value"`
+}
+
+func (c *Cache) encode(value any, ttl time.Duration) ([]byte, error) {
+	v, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling cache value: %w", err)
+	}
+	data, err := json.Marshal(entry{FreshUntil: c.now().Add(ttl).Unix(), Value: v})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling cache entry: %w", err)
+	}
+	return data, nil
+}
+
+func (c *Cache) decode(data []byte, dest any) error {
+	if bytes.Equal(data, notFoundMarker) {
+		return ErrNotFound
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return fmt.Errorf("decoding cache entry: %w", err)
+	}
+	if len(e.Value) == 0 {
+		return errors.New("decoding cache entry: no value")
+	}
+	if err := json.Unmarshal(e.Value, dest); err != nil {
+		return fmt.Errorf("decoding cache value: %w", err)
+	}
+	if c.now().Unix() >= e.FreshUntil {
+		return ErrStale
+	}
+	return nil
+}
+
+// get reads key from the local tier, then the backend. Backend hits are
+// copied into the local tier. While the backend is down, lookups miss.
+func (c *Cache) get(ctx context.Context, key string, dest any) error {
+	if c.local != nil {
+		if data, ok := c.local.get(key); ok {
+			c.localStats.hit()
+			return c.decode(data, dest)
+		}
+		c.localStats.miss()
+	}
+
+	if !c.backendUp() {
+		return ErrMiss
+	}
+	data, err := c.backend.Get(ctx, key)
+	if err != nil && !errors.Is(err, ErrMiss) {
+		c.backendDown(err)
+		return ErrMiss
+	}
+	c.backendUpAgain()
+	if err != nil {
+		c.backendStats.miss()
+		return err
+	}
+	c.backendStats.hit()
+	if c.local != nil {
+		c.local.set(key, data, c.local.ttl)
+	}
+	return c.decode(data, dest)
+}
+
+// set caches value as fresh for ttl, then stale for another stale. Only
+// the backend keeps the stale copy.
+func (c *Cache) set(ctx context.Context, key string, value any, ttl, stale time.Duration) error {
+	data, err := c.encode(value, ttl)
+	if err != nil {
+		return err
+	}
+	return c.setRaw(ctx, key, data, ttl, ttl+stale)
+}
+
+// setRaw writes data to the local tier and, unless it's down, the backend.
+// A backend error marks it down and isn't returned: caching is
+// best-effort.
+func (c *Cache) setRaw(ctx context.Context, key string, data []byte, localTTL, backendTTL time.Duration) error {
+	if c.local != nil {
+		c.local.set(key, data, localTTL)
+	}
+	if !c.backendUp() {
+		return nil
+	}
+	if err := c.backend.Set(ctx, key, data, backendTTL); err != nil {
+		c.backendDown(err)
+		return nil
+	}
+	c.backendUpAgain()
+	return nil
+}
+
+// backendUp reports whether the backend should be tried: it's healthy, or
+// backendRetry has passed since it last failed.
+func (c *Cache) backendUp() bool {
+	until := c.downUntil.Load()
+	return until == 0 || c.now().UnixNano() >= until
+}
+
+// backendDown skips the backend for backendRetry after err. Caller
+// cancellations don't count.
+func (c *Cache) backendDown(err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	if c.downUntil.Swap(c.now().Add(backendRetry).UnixNano()) == 0 {
+		slog.Warn("cache backend unavailable, continuing without it", "backend", c.backend.Name(), "error", err)
+	}
+}
+
+// backendUpAgain clears the down state after a backend call succeeds.
+func (c *Cache) backendUpAgain() {
+	if c.downUntil.Load() != 0 && c.downUntil.Swap(0) != 0 {
+		slog.Info("cache backend recovered", "backend", c.backend.Name())
+	}
+}
+
+// AddressKey returns a stable cache key for an address. Case and runs of
+// whitespace are ignored; the parts are hashed to keep keys short.
+func AddressKey(street, city, state, zip string) string {
+	parts := []string{street, city, state, zip}
+	for i, p := range parts {
+		parts[i] = strings.Join(strings.Fields(strings.ToLower(p)), " ")
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
+// versionPrefix starts every versioned key: tax:v:<version>:...
+const versionPrefix = "tax:v:"
+
+func keyForZIP(version, zip string) string {
+	return versionPrefix + version + ":zip:" + zip
+}
+
+func keyForAddress(version, addrKey string) string {
+	return versionPrefix + version + ":addr:" + addrKey
+}
+
+func keyForGeocode(addrKey string) string {
+	return "tax:geo:" + addrKey
+}
@@ -0,0 +1,206 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAddressKey_IgnoresCaseAndWhitespace(t *testing.T) {
+	a := AddressKey("123 Main St", "Beverly Hills", "CA", "90210")
+	b := AddressKey("  123  MAIN st ", "beverly   hills", "ca", "90210")
+	if a != b {
+		t.Fatalf("expected equal keys, got %s and %s", a, b)
+	}
+}
+
+func TestAddressKey_DistinguishesFields(t *testing.T) {
+	// Moving text between fields must not produce the same key.
+	a := AddressKey("123 Main St", "Springfield", "IL", "62701")
+	b := AddressKey("123 Main St Springfield", "", "IL", "62701")
+	if a == b {
+		t.Fatal("expected different keys for different field splits")
+	}
+}
+
+func TestPin_KeepsVersionAcrossSwitch(t *testing.T) {
+	c := New(NewMemory(100), Options{TTL: time.Hour})
+
+	c.SetVersion("v1")
+	ctx := c.Pin(context.Background())
+	if !c.SetVersion("v2") {
+		t.Fatal("SetVersion(v2) reported no change")
+	}
+	if c.SetVersion("v2") {
+		t.Error("SetVersion(v2) again reported a change")
+	}
+
+	if got := keyForZIP(c.PinnedVersion(ctx), "90210"); got != "tax:v:v1:zip:90210" {
+		t.Errorf("pinned key = %q, want the v1 namespace", got)
+	}
+	if got := keyForZIP(c.PinnedVersion(context.Background()), "90210"); got != "tax:v:v2:zip:90210" {
+		t.Errorf("unpinned key = %q, want the v2 namespace", got)
+	}
+}
+
+func TestAdoptAnnounced_OnlyVersionsTheSourceReports(t *testing.T) {
+	c := New(NewMemory(100), Options{TTL: time.Hour})
+	c.SetVersion("v1")
+	source := "v1"
+	current := func(context.Context) (string, error) { return source, nil }
+	ctx := context.Background()
+
+	// Another instance's source says v2; this one's still says v1.
+	if c.adoptAnnounced(ctx, "v2", current) || c.Version() != "v1" {
+		t.Fatalf("switched to v2 while the source reports v1")
+	}
+
+	source = "v2"
+	if !c.adoptAnnounced(ctx, "v2", current) || c.Version() != "v2" {
+		t.Fatalf("Version = %q, want v2 once the source reports it", c.Version())
+	}
+
+	failing := func(context.Context) (string, error) { return "", errors.New("connection refused") }
+	if c.adoptAnnounced(ctx, "v3", failing) {
+		t.Error("switched to v3 without confirming it")
+	}
+}
+
+func TestSetVersion_ClearsLocalTier(t *testing.T) {
+	c := New(NewMemory(100), Options{TTL: time.Hour, LocalEntries: 10, LocalTTL: time.Minute})
+
+	c.local.set(keyForZIP(c.Version(), "90210"), []byte(`{}`), 0)
+	c.SetVersion("v2")
+	if n := c.Stats().LocalEntries; n != 0 {
+		t.Errorf("LocalEntries = %d after a version switch, want 0", n)
+	}
+}
+
+func TestDecode_NotFoundMarker(t *testing.T) {
+	c := New(NewMemory(100), Options{TTL: time.Hour})
+
+	var dest map[string]any
+	if err := c.decode(notFoundMarker, &dest); !errors.Is(err, ErrNotFound) {
+		t.Errorf("decode(marker) = %v, want ErrNotFound", err)
+	}
+	data, err := c.encode(map[string]any{"zip_code": "90210"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.decode(data, &dest); err != nil {
+		t.Errorf("decode(response) = %v", err)
+	}
+}
+
+func TestDecode_Stale(t *testing.T) {
+	c := New(NewMemory(100), Options{TTL: time.Hour, StaleTTL: 24 * time.Hour})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	data, err := c.encode(map[string]string{"zip_code": "90210"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	var dest map[string]string
+	if err := c.decode(data, &dest); !errors.Is(err, ErrStale) {
+		t.Fatalf("decode past TTL = %v, want ErrStale", err)
+	}
+	if dest["zip_code"] != "90210" {
+		t.Errorf("stale decode left dest = %v, want the cached value", dest)
+	}
+}
+
+func TestGet_NotFoundFromLocalTier(t *testing.T) {
+	c := New(NewMemory(100), Options{TTL: time.Hour, NegativeTTL: time.Minute, LocalEntries: 10, LocalTTL: time.Minute})
+
+	// Served from the local tier without touching the backend.
+	c.local.set(keyForZIP(c.Version(), "00000"), notFoundMarker, time.Minute)
+	var dest map[string]any
+	if err := c.Get(context.Background(), "00000", &dest); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemory_ZeroTTL(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(10)
+	if err := m.Set(ctx, "k", []byte("v"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetMany(ctx, map[string][]byte{"k2": []byte("v2")}, 0); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"k": "v", "k2": "v2"} {
+		if got, err := m.Get(ctx, key); err != nil || string(got) != want {
+			t.Errorf("Get(%s) = %q, %v; want %q set with no TTL", key, got, err, want)
+		}
+	}
+}
+
+func TestMemory_SetGetAndPurge(t *testing.T) {
+	c := New(NewMemory(100), Options{TTL: time.Hour, StaleTTL: time.Hour})
+	ctx := context.Background()
+
+	c.SetVersion("v1")
+	if err := c.Set(ctx, "90210", map[string]string{"zip_code": "90210"}); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]string
+	if err := c.Get(ctx, "90210", &got); err != nil || got["zip_code"] != "90210" {
+		t.Fatalf("Get = %v, %v", got, err)
+	}
+
+	c.SetVersion("v2")
+	c.PurgeStale(ctx)
+	c.SetVersion("v1")
+	if err := c.Get(ctx, "90210", &got); !errors.Is(err, ErrMiss) {
+		t.Errorf("Get after purge = %v, want ErrMiss", err)
+	}
+}
+
+// failingBackend errors on every read and write.
+type failingBackend struct {
+	*Memory
+	calls int
+}
+
+func (f *failingBackend) Get(context.Context, string) ([]byte, error) {
+	f.calls++
+	return nil, errors.New("connection refused")
+}
+
+func (f *failingBackend) Set(context.Context, string, []byte, time.Duration) error {
+	f.calls++
+	return errors.New("connection refused")
+}
+
+func TestBackendDown_DegradesToMiss(t *testing.T) {
+	backend := &failingBackend{Memory: NewMemory(10)}
+	c := New(backend, Options{TTL: time.Hour})
+	now := time.Unix(1_700_000_000, 0)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	var dest map[string]any
+	if err := c.Get(ctx, "90210", &dest); !errors.Is(err, ErrMiss) {
+		t.Fatalf("Get = %v, want ErrMiss", err)
+	}
+	if err := c.Set(ctx, "90210", map[string]any{}); err != nil {
+		t.Errorf("Set = %v, want nil while the backend is down", err)
+	}
+	if backend.calls != 1 {
+		t.Errorf("backend called %d times, want 1 while marked down", backend.calls)
+	}
+	if !c.Stats().BackendDown {
+		t.Error("Stats().BackendDown = false, want true")
+	}
+
+	now = now.Add(backendRetry)
+	_ = c.Get(ctx, "90210", &dest)
+	if backend.calls != 2 {
+		t.Errorf("backend called %d times, want a retry after backendRetry", backend.calls)
+	}
+}
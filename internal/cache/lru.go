@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lru is a size-bounded, TTL-aware in-process cache of encoded values. It
+// holds the hottest keys so they skip the backend round trip.
+type lru struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	order      *list.List // front is most recently used
+	items      map[string]*list.Element
+	now        func() time.Time
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// newLRU returns an lru holding up to maxEntries values for at most ttl
+// each. A zero ttl leaves each entry the TTL it's set with, and an entry set
+// with a zero TTL too never expires.
+func newLRU(maxEntries int, ttl time.Duration) *lru {
+	return &lru{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+func (l *lru) get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*lruEntry)
+	if !e.expires.IsZero() && !l.now().Before(e.expires) {
+		l.remove(el)
+		return nil, false
+	}
+	l.order.MoveToFront(el)
+	return e.value, true
+}
+
+// set stores value for the shorter of ttl and the lru's own TTL, evicting
+// the least recently used entry when full. Zero for both keeps it until
+// it's evicted.
+func (l *lru) set(key string, value []byte, ttl time.Duration) {
+	if l.ttl > 0 && (ttl <= 0 || ttl > l.ttl) {
+		ttl = l.ttl
+	}
+	var expires time.Time
+	if ttl > 0 {
+		expires = l.now().Add(ttl)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if el, ok := l.items[key]; ok {
+		e := el.Value.(*lruEntry)
+		e.value, e.expires = value, expires
+		l.order.MoveToFront(el)
+		return
+	}
+	l.items[key] = l.order.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	for l.order.Len() > l.maxEntries {
+		l.remove(l.order.Back())
+	}
+}
+
+// purge drops every entry.
+func (l *lru) purge() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.order.Init()
+	clear(l.items)
+}
+
+// deleteFunc drops the entries whose key matches and returns how many.
+func (l *lru) deleteFunc(match func(key string) bool) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var n int
+	for key, el := range l.items {
+		if match(key) {
+			l.remove(el)
+			n++
+		}
+	}
+	return n
+}
+
+func (l *lru) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+func (l *lru) remove(el *list.Element) {
+	l.order.Remove(el)
+	delete(l.items, el.Value.(*lruEntry).key)
+}
@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	l := newLRU(2, time.Minute)
+	l.set("a", []byte("1"), 0)
+	l.set("b", []byte("2"), 0)
+	l.get("a") // b is now least recently used
+	l.set("c", []byte("3"), 0)
+
+	if _, ok := l.get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	for _, k := range []string{"a", "c"} {
+		if _, ok := l.get(k); !ok {
+			t.Errorf("%s should still be cached", k)
+		}
+	}
+	if l.len() != 2 {
+		t.Errorf("len = %d, want 2", l.len())
+	}
+}
+
+func TestLRU_Expires(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newLRU(10, time.Minute)
+	l.now = func() time.Time { return now }
+
+	l.set("short", []byte("1"), 10*time.Second)
+	l.set("long", []byte("2"), time.Hour) // capped at the lru's minute
+
+	now = now.Add(30 * time.Second)
+	if _, ok := l.get("short"); ok {
+		t.Error("short should have expired at its own TTL")
+	}
+	if _, ok := l.get("long"); !ok {
+		t.Error("long should still be cached")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := l.get("long"); ok {
+		t.Error("long should have expired at the lru TTL")
+	}
+	if l.len() != 0 {
+		t.Errorf("len = %d, want expired entries removed", l.len())
+	}
+}
+
+func TestLRU_ZeroTTLNeverExpires(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newLRU(10, 0)
+	l.now = func() time.Time { return now }
+
+	l.set("forever", []byte("1"), 0)
+	now = now.Add(365 * 24 * time.Hour)
+	if v, ok := l.get("forever"); !ok || string(v) != "1" {
+		t.Errorf("get = %q, %v; want the value set with no TTL", v, ok)
+	}
+}
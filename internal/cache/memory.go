@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Memory is a Backend held in the process, for single-instance
+// deployments and local development without Redis. Entries aren't shared
+// between instances and don't survive a restart.
+type Memory struct {
+	entries *lru
+}
+
+// NewMemory returns a Memory backend holding up to maxEntries values,
+// evicting the least recently used.
+func NewMemory(maxEntries int) *Memory {
+	return &Memory{entries: newLRU(maxEntries, 0)}
+}
+
+func (m *Memory) Get(_ context.Context, key string) ([]byte, error) {
+	if data, ok := m.entries.get(key); ok {
+		return data, nil
+	}
+	return nil, ErrMiss
+}
+
+func (m *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.entries.set(key, value, ttl)
+	return nil
+}
+
+func (m *Memory) SetMany(_ context.Context, entries map[string][]byte, ttl time.Duration) error {
+	for key, value := range entries {
+		m.entries.set(key, value, ttl)
+	}
+	return nil
+}
+
+func (m *Memory) DeletePrefix(_ context.Context, prefix, keep string) (int, error) {
+	return m.entries.deleteFunc(func(key string) bool {
+		return strings.HasPrefix(key, prefix) && !strings.HasPrefix(key, keep)
+	}), nil
+}
+
+// Publish does nothing: there are no other instances to tell.
+func (m *Memory) Publish(context.Context, string, string) error { return nil }
+
+// Subscribe returns immediately: no other instance publishes.
+func (m *Memory) Subscribe(context.Context, string, func(string)) {}
+
+func (m *Memory) Ping(context.Context) error { return nil }
+
+func (m *Memory) Close() error { return nil }
+
+func (m *Memory) Name() string { return "memory" }
@@ -2,55 +2,118 @@ package cache
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
-type Cache struct {
+// Redis is a Backend shared by every instance pointed at the same server.
+type Redis struct {
 	client *redis.Client
-	ttl    time.Duration
 }
 
-func New(redisURL string, ttlHours int) (*Cache, error) {
+// NewRedis returns a Redis backend for redisURL. It doesn't connect until
+// first use, so the server starts while Redis is down.
+func NewRedis(redisURL string) (*Redis, error) {
 	opts, err := redis.ParseURL(redisURL)
 	if err != nil {
 		return nil, fmt.Errorf("parsing redis URL: %w", err)
 	}
+	return &Redis{client: redis.NewClient(opts)}, nil
+}
 
-	client := redis.NewClient(opts)
-	return &Cache{
-		client: client,
-		ttl:    time.Duration(ttlHours) * time.Hour,
-	}, nil
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := r.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMiss
+	}
+	return data, err
 }
 
-func (c *Cache) Close() error {
-	return c.client.Close()
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, key, value, ttl).Err()
 }
 
-func (c *Cache) Ping(ctx context.Context) error {
-	return c.client.Ping(ctx).Err()
+// SetMany writes every entry in one pipelined round trip.
+func (r *Redis) SetMany(ctx context.Context, entries map[string][]byte, ttl time.Duration) error {
+	pipe := r.client.Pipeline()
+	for key, value := range entries {
+		pipe.Set(ctx, key, value, ttl)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
-func (c *Cache) Get(ctx context.Context, zipCode string, dest any) error {
-	val, err := c.client.Get(ctx, keyForZIP(zipCode)).Result()
-	if err != nil {
-		return err
+// DeletePrefix scans for matching keys and unlinks them in batches, so it
+// doesn't block Redis. It's safe to run on several instances at once.
+func (r *Redis) DeletePrefix(ctx context.Context, prefix, keep string) (int, error) {
+	var deleted int
+	var batch []string
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := r.client.Unlink(ctx, batch...).Err(); err != nil {
+			return err
+		}
+		deleted += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	iter := r.client.Scan(ctx, 0, prefix+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		if key := iter.Val(); !strings.HasPrefix(key, keep) {
+			batch = append(batch, key)
+			if len(batch) == 500 {
+				if err := flush(); err != nil {
+					return deleted, err
+				}
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return deleted, err
 	}
-	return json.Unmarshal([]byte(val), dest)
+	return deleted, iter.Err()
 }
 
-func (c *Cache) Set(ctx context.Context, zipCode string, value any) error {
-	data, err := json.Marshal(value)
-	if err != nil {
-		return fmt.Errorf("marshaling cache value: %w", err)
+func (r *Redis) Publish(ctx context.Context, channel, message string) error {
+	return r.client.Publish(ctx, channel, message).Err()
+}
+
+func (r *Redis) Subscribe(ctx context.Context, channel string, handle func(string)) {
+	sub := r.client.Subscribe(ctx, channel)
+	defer func() {
+		if err := sub.Close(); err != nil {
+			slog.Warn("closing redis subscription", "error", err)
+		}
+	}()
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			handle(msg.Payload)
+		}
 	}
-	return c.client.Set(ctx, keyForZIP(zipCode), data, c.ttl).Err()
 }
 
-func keyForZIP(zip string) string {
-	return "tax:zip:" + zip
+func (r *Redis) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
 }
+
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
+
+func (r *Redis) Name() string { return "redis" }
@@ -0,0 +1,43 @@
+package cache
+
+import "sync/atomic"
+
+// Stats reports hits and misses per cache tier since startup.
+type Stats struct {
+	Local        TierStats `json:"local"`
+	Backend      TierStats `json:"backend"`
+	LocalEntries int       `json:"local_entries"`
+	// BackendDown is true while the backend is skipped after an error.
+	BackendDown bool `json:"backend_down"`
+}
+
+// TierStats counts lookups answered (Hits) and passed on (Misses) by one
+// tier. Backend errors other than a missing key count as neither.
+type TierStats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+}
+
+type tierCounters struct {
+	hits, misses atomic.Uint64
+}
+
+func (t *tierCounters) hit()  { t.hits.Add(1) }
+func (t *tierCounters) miss() { t.misses.Add(1) }
+
+func (t *tierCounters) snapshot() TierStats {
+	return TierStats{Hits: t.hits.Load(), Misses: t.misses.Load()}
+}
+
+// Stats returns the current per-tier counters.
+func (c *Cache) Stats() Stats {
+	s := Stats{
+		Local:       c.localStats.snapshot(),
+		Backend:     c.backendStats.snapshot(),
+		BackendDown: !c.backendUp(),
+	}
+	if c.local != nil {
+		s.LocalEntries = c.local.len()
+	}
+	return s
+}
@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// purgeTimeout bounds one background sweep of old namespaces.
+const purgeTimeout = 5 * time.Minute
+
+// Version returns the dataset version rate responses are cached under.
+func (c *Cache) Version() string {
+	return *c.version.Load()
+}
+
+// SetVersion switches rate responses to the namespace for version and
+// reports whether it changed. Entries under the previous version are no
+// longer read; the local tier is cleared and PurgeStale removes them from
+// the backend.
+func (c *Cache) SetVersion(version string) bool {
+	old := c.version.Swap(&version)
+	changed := old == nil || *old != version
+	if changed && c.local != nil {
+		c.local.purge()
+	}
+	return changed
+}
+
+type versionKey struct{}
+
+// Pin fixes the dataset version for cache reads and writes made with the
+// returned context. A lookup pins before it resolves, so a response built
+// from the old data isn't stored under a version that was switched to
+// mid-request.
+func (c *Cache) Pin(ctx context.Context) context.Context {
+	return context.WithValue(ctx, versionKey{}, c.Version())
+}
+
+// PinnedVersion returns the version ctx was pinned to, or the current one.
+func (c *Cache) PinnedVersion(ctx context.Context) string {
+	if v, ok := ctx.Value(versionKey{}).(string); ok {
+		return v
+	}
+	return c.Version()
+}
+
+// WatchVersion polls current every interval and switches namespaces when the
+// dataset version changes, then tells other instances over pub/sub and
+// purges the old namespaces in the background. It runs until ctx is
+// canceled. Errors keep the current version.
+func (c *Cache) WatchVersion(ctx context.Context, interval time.Duration, current func(context.Context) (string, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		version, err := current(ctx)
+		if err != nil {
+			slog.Warn("checking dataset version", "error", err)
+			continue
+		}
+		old := c.Version()
+		if c.SetVersion(version) {
+			slog.Info("dataset version changed, switching cache namespace", "from", old, "to", version)
+			if err := c.backend.Publish(ctx, invalidateChannel, version); err != nil {
+				slog.Warn("publishing cache invalidation", "error", err)
+			}
+			go c.PurgeStale(ctx)
+		}
+	}
+}
+
+// invalidateChannel carries new dataset versions between instances.
+const invalidateChannel = "tax:cache:invalidate"
+
+// ListenInvalidations switches to versions announced by other instances, so
+// every instance drops its local tier as soon as one of them sees a
+// promotion rather than on its own next poll. current is the same source
+// WatchVersion polls. It runs until ctx is canceled, or returns at once for
+// a backend with no other instances.
+func (c *Cache) ListenInvalidations(ctx context.Context, current func(context.Context) (string, error)) {
+	c.backend.Subscribe(ctx, invalidateChannel, func(version string) {
+		if c.adoptAnnounced(ctx, version, current) {
+			slog.Info("cache invalidated by another instance", "version", version)
+		}
+	})
+}
+
+// adoptAnnounced switches to an announced version only if current reports
+// it too. An instance whose source has a different version, such as one
+// serving a snapshot while sharing Redis with database-backed instances,
+// ignores the announcement rather than switching, flipping back on its next
+// poll and announcing its own version in turn.
+func (c *Cache) adoptAnnounced(ctx context.Context, version string, current func(context.Context) (string, error)) bool {
+	if version == c.Version() {
+		return false
+	}
+	local, err := current(ctx)
+	if err != nil {
+		slog.Warn("checking announced dataset version", "version", version, "error", err)
+		return false
+	}
+	if local != version {
+		slog.Debug("ignoring announced dataset version", "version", version, "source", local)
+		return false
+	}
+	return c.SetVersion(version)
+}
+
+// PurgeStale deletes rate responses cached under any version other than the
+// current one. It's safe to run on several instances at once.
+func (c *Cache) PurgeStale(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, purgeTimeout)
+	defer cancel()
+
+	keep := versionPrefix + c.Version() + ":"
+	deleted, err := c.backend.DeletePrefix(ctx, versionPrefix, keep)
+	if err != nil {
+		slog.Warn("purging stale cache entries", "error", err)
+	}
+	if deleted > 0 {
+		slog.Info("purged stale cache entries", "count", deleted)
+	}
+}
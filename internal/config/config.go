@@ -4,17 +4,39 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
 type Config struct {
 	Port              string
 	DatabaseURL       string
+	ReplicaURLs       []string
+	ReplicaMaxLagS    int
+	SnapshotFile      string
 	RedisURL          string
+	CacheBackend      string
+	MemoryCacheSize   int
 	SentryDSN         string
 	APIKeySecret      string
 	RapidAPISecret    string
+	BoundaryFiles     []string
+	GeocoderChain     []string
+	NominatimURL      string
 	RateLimitRPS      int
 	CacheTTLHrs       int
+	CacheVersionPollS int
+	NegativeTTLMin    int
+	CacheStaleTTLHrs  int
+	LocalCacheSize    int
+	LocalCacheTTLS    int
+	CacheWarmOnStart  bool
+	GeocodeHitTTLHrs  int
+	GeocodeMissTTLMin int
+	GeocodeRetries    int
+	GeocodeAttemptMs  int
+	GeocodeBudgetMs   int
+	BreakerFailures   int
+	BreakerCooldownS  int
 	LogLevel          string
 	Environment       string
 }
@@ -23,26 +45,66 @@ func Load() (*Config, error) {
 	cfg := &Config{
 		Port:         envOr("PORT", "8080"),
 		DatabaseURL:  os.Getenv("DATABASE_URL"),
+		SnapshotFile: os.Getenv("SNAPSHOT_FILE"),
+		ReplicaURLs:  envList("DATABASE_REPLICA_URLS"),
 		RedisURL:     os.Getenv("REDIS_URL"),
+		CacheBackend: os.Getenv("CACHE_BACKEND"),
 		SentryDSN:    os.Getenv("SENTRY_DSN"),
 		APIKeySecret:   os.Getenv("API_KEY_SECRET"),
 		RapidAPISecret: os.Getenv("RAPIDAPI_PROXY_SECRET"),
+		BoundaryFiles:  envList("BOUNDARY_FILES"),
+		GeocoderChain:  envList("GEOCODER_CHAIN"),
+		NominatimURL:   os.Getenv("NOMINATIM_URL"),
+		MemoryCacheSize:   envOrInt("MEMORY_CACHE_SIZE", 100000),
 		RateLimitRPS: envOrInt("RATE_LIMIT_RPS", 10),
 		CacheTTLHrs:  envOrInt("CACHE_TTL_HOURS", 24),
+		CacheVersionPollS: envOrInt("CACHE_VERSION_POLL_SECONDS", 30),
+		ReplicaMaxLagS:    envOrInt("DATABASE_REPLICA_MAX_LAG_SECONDS", 30),
+		NegativeTTLMin:    envOrInt("CACHE_NEGATIVE_TTL_MINUTES", 10),
+		CacheStaleTTLHrs:  envOrInt("CACHE_STALE_TTL_HOURS", 168),
+		LocalCacheSize:    envOrInt("LOCAL_CACHE_SIZE", 10000),
+		LocalCacheTTLS:    envOrInt("LOCAL_CACHE_TTL_SECONDS", 60),
+		CacheWarmOnStart:  envOrBool("CACHE_WARM_ON_START", false),
+		GeocodeHitTTLHrs:  envOrInt("GEOCODE_CACHE_TTL_HOURS", 168),
+		GeocodeMissTTLMin: envOrInt("GEOCODE_MISS_TTL_MINUTES", 30),
+		GeocodeRetries:    envOrInt("GEOCODER_RETRIES", 1),
+		GeocodeAttemptMs:  envOrInt("GEOCODER_ATTEMPT_TIMEOUT_MS", 1500),
+		GeocodeBudgetMs:   envOrInt("GEOCODER_BUDGET_MS", 3000),
+		BreakerFailures:   envOrInt("GEOCODER_BREAKER_FAILURES", 5),
+		BreakerCooldownS:  envOrInt("GEOCODER_BREAKER_COOLDOWN_SECONDS", 30),
 		LogLevel:     envOr("LOG_LEVEL", "info"),
 		Environment:  envOr("ENVIRONMENT", "production"),
 	}
 
-	if cfg.DatabaseURL == "" {
-		return nil, fmt.Errorf("DATABASE_URL is required")
+	if len(cfg.GeocoderChain) == 0 {
+		cfg.GeocoderChain = []string{"census"}
 	}
-	if cfg.RedisURL == "" {
-		return nil, fmt.Errorf("REDIS_URL is required")
+
+	// A snapshot file replaces the database.
+	if cfg.DatabaseURL == "" && cfg.SnapshotFile == "" {
+		return nil, fmt.Errorf("DATABASE_URL or SNAPSHOT_FILE is required")
 	}
 	if cfg.APIKeySecret == "" {
 		return nil, fmt.Errorf("API_KEY_SECRET is required")
 	}
 
+	// Cache in Redis when it's configured, otherwise in process.
+	if cfg.CacheBackend == "" {
+		cfg.CacheBackend = "memory"
+		if cfg.RedisURL != "" {
+			cfg.CacheBackend = "redis"
+		}
+	}
+	switch cfg.CacheBackend {
+	case "redis":
+		if cfg.RedisURL == "" {
+			return nil, fmt.Errorf("REDIS_URL is required for CACHE_BACKEND=redis")
+		}
+	case "memory":
+	default:
+		return nil, fmt.Errorf("unknown CACHE_BACKEND %q", cfg.CacheBackend)
+	}
+
 	return cfg, nil
 }
 
@@ -64,3 +126,26 @@ func envOrInt(key string, fallback int) int {
 	}
 	return n
 }
+
+func envOrBool(key string, fallback bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fallback
+	}
+	return b
+}
+
+// envList splits a comma-separated variable, dropping empty entries.
+func envList(key string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,274 @@
+package geocoder
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the provider while its breaker
+// is open, so callers fall back immediately instead of waiting on a
+// provider that is known to be failing.
+var ErrCircuitOpen = errors.New("geocoder circuit open")
+
+// Unavailable reports whether err means no provider was called because
+// every breaker was open. It's false when any provider actually failed,
+// including in the joined errors a Chain returns.
+func Unavailable(err error) bool {
+	var joined interface{ Unwrap() []error }
+	if errors.As(err, &joined) {
+		errs := joined.Unwrap()
+		for _, e := range errs {
+			if !Unavailable(e) {
+				return false
+			}
+		}
+		return len(errs) > 0
+	}
+	return errors.Is(err, ErrCircuitOpen)
+}
+
+// Breaker states.
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half_open"
+)
+
+// Policy configures retries and the circuit breaker for one provider.
+type Policy struct {
+	// MaxAttempts is the total number of tries per call, including the first.
+	MaxAttempts int
+	// AttemptTimeout bounds a single try.
+	AttemptTimeout time.Duration
+	// Budget bounds the whole call, retries and backoff included. Zero
+	// means only the caller's deadline applies.
+	Budget time.Duration
+	// Backoff is the wait before the first retry; it doubles on each one.
+	Backoff time.Duration
+	// FailureThreshold is the number of consecutive failed calls that
+	// opens the breaker.
+	FailureThreshold int
+	// Cooldown is how long the breaker stays open before letting a single
+	// trial call through.
+	Cooldown time.Duration
+}
+
+// DefaultPolicy keeps a slow provider well inside a checkout request.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:      2,
+		AttemptTimeout:   1500 * time.Millisecond,
+		Budget:           3 * time.Second,
+		Backoff:          100 * time.Millisecond,
+		FailureThreshold: 5,
+		Cooldown:         30 * time.Second,
+	}
+}
+
+// BreakerStatus is a snapshot of a breaker for health reporting.
+type BreakerStatus struct {
+	Name                string     `json:"name"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+}
+
+// Breaker retries transient provider failures within a deadline budget and
+// stops calling the provider after repeated failures. One Breaker should
+// guard every endpoint of the same upstream service, since they fail
+// together.
+type Breaker struct {
+	name   string
+	policy Policy
+	now    func() time.Time
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+}
+
+func NewBreaker(name string, p Policy) *Breaker {
+	if p.MaxAttempts < 1 {
+		p.MaxAttempts = 1
+	}
+	return &Breaker{name: name, policy: p, now: time.Now, state: StateClosed}
+}
+
+// Geocoder wraps g so its calls go through the breaker.
+func (b *Breaker) Geocoder(g Geocoder) Geocoder {
+	return &breakerGeocoder{breaker: b, next: g}
+}
+
+// Reverser wraps rv so its calls go through the breaker.
+func (b *Breaker) Reverser(rv Reverser) Reverser {
+	return &breakerReverser{breaker: b, next: rv}
+}
+
+// Status reports the breaker's current state.
+func (b *Breaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	st := BreakerStatus{Name: b.name, State: b.state, ConsecutiveFailures: b.failures}
+	if b.state == StateOpen {
+		retryAt := b.openedAt.Add(b.policy.Cooldown)
+		st.RetryAt = &retryAt
+	}
+	return st
+}
+
+// call runs fn with retries and reports the outcome to the breaker.
+func (b *Breaker) call(ctx context.Context, fn func(context.Context) (*Result, error)) (*Result, error) {
+	if !b.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	if b.policy.Budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.policy.Budget)
+		defer cancel()
+	}
+
+	var (
+		result *Result
+		err    error
+	)
+	backoff := b.policy.Backoff
+	for attempt := 1; ; attempt++ {
+		result, err = b.attempt(ctx, fn)
+		if err == nil || !retryable(err) || attempt >= b.policy.MaxAttempts {
+			break
+		}
+		if !sleep(ctx, backoff) {
+			break
+		}
+		backoff *= 2
+	}
+
+	b.record(err)
+	return result, err
+}
+
+func (b *Breaker) attempt(ctx context.Context, fn func(context.Context) (*Result, error)) (*Result, error) {
+	if b.policy.AttemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.policy.AttemptTimeout)
+		defer cancel()
+	}
+	return fn(ctx)
+}
+
+// allow reports whether a call may proceed. After the cooldown, exactly one
+// trial call is let through; its outcome closes or re-opens the breaker.
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.policy.Cooldown {
+			return false
+		}
+		b.state = StateHalfOpen
+		return true
+	case StateHalfOpen:
+		return false // trial call in flight
+	default:
+		return true
+	}
+}
+
+// record updates the breaker after a call. Only provider failures count;
+// a no-match, a rejected request or a caller cancellation says nothing
+// about the provider's health.
+func (b *Breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err != nil && !retryable(err) {
+		if b.state == StateHalfOpen {
+			if errors.Is(err, context.Canceled) {
+				b.state = StateOpen // inconclusive; let the next call try again
+			} else {
+				b.state = StateClosed // the provider answered
+				b.failures = 0
+			}
+		}
+		return
+	}
+
+	if err == nil {
+		b.state = StateClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.policy.FailureThreshold {
+		b.state = StateOpen
+		b.openedAt = b.now()
+	}
+}
+
+// retryable reports whether err looks like a transient provider failure:
+// a transport error, a timeout, a 5xx or a 429. Cancellation by the caller
+// is not.
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.Code >= http.StatusInternalServerError || se.Code == http.StatusTooManyRequests
+	}
+	var de *decodeError
+	return !errors.As(err, &de)
+}
+
+// decodeError marks a malformed provider response, which a retry won't fix.
+type decodeError struct{ err error }
+
+func (e *decodeError) Error() string { return e.err.Error() }
+func (e *decodeError) Unwrap() error { return e.err }
+
+// sleep waits for d or until ctx is done, reporting whether it waited the
+// full duration.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+type breakerGeocoder struct {
+	breaker *Breaker
+	next    Geocoder
+}
+
+func (g *breakerGeocoder) Geocode(ctx context.Context, street, city, state, zip string) (*Result, error) {
+	return g.breaker.call(ctx, func(ctx context.Context) (*Result, error) {
+		return g.next.Geocode(ctx, street, city, state, zip)
+	})
+}
+
+type breakerReverser struct {
+	breaker *Breaker
+	next    Reverser
+}
+
+func (r *breakerReverser) Reverse(ctx context.Context, lat, lng float64) (*Result, error) {
+	return r.breaker.call(ctx, func(ctx context.Context) (*Result, error) {
+		return r.next.Reverse(ctx, lat, lng)
+	})
+}
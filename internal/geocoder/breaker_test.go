@@ -0,0 +1,193 @@
+package geocoder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// countingGeocoder returns errs in order (then nil) and counts calls.
+type countingGeocoder struct {
+	calls int
+	errs  []error
+}
+
+func (g *countingGeocoder) Geocode(context.Context, string, string, string, string) (*Result, error) {
+	g.calls++
+	if g.calls <= len(g.errs) {
+		return nil, g.errs[g.calls-1]
+	}
+	return &Result{StateFIPS: "06"}, nil
+}
+
+func testPolicy() Policy {
+	return Policy{MaxAttempts: 2, FailureThreshold: 2, Cooldown: time.Minute}
+}
+
+func TestBreaker_RetriesTransientErrors(t *testing.T) {
+	g := &countingGeocoder{errs: []error{&StatusError{Provider: "census", Code: 503}}}
+	b := NewBreaker("census", testPolicy())
+
+	result, err := b.Geocoder(g).Geocode(context.Background(), "1 Main St", "", "", "90210")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result == nil || g.calls != 2 {
+		t.Fatalf("expected success on retry, got %+v after %d calls", result, g.calls)
+	}
+	if st := b.Status(); st.State != StateClosed || st.ConsecutiveFailures != 0 {
+		t.Errorf("unexpected status: %+v", st)
+	}
+}
+
+func TestBreaker_DoesNotRetryClientErrors(t *testing.T) {
+	g := &countingGeocoder{errs: []error{&StatusError{Provider: "census", Code: 400}}}
+	b := NewBreaker("census", testPolicy())
+
+	if _, err := b.Geocoder(g).Geocode(context.Background(), "1 Main St", "", "", "90210"); err == nil {
+		t.Fatal("expected error")
+	}
+	if g.calls != 1 {
+		t.Errorf("calls = %d, want 1", g.calls)
+	}
+	if st := b.Status(); st.ConsecutiveFailures != 0 {
+		t.Errorf("client error should not count as a failure: %+v", st)
+	}
+}
+
+func TestBreaker_OpensAndRecovers(t *testing.T) {
+	down := errors.New("connection refused")
+	g := &countingGeocoder{errs: []error{down, down, down, down}}
+	b := NewBreaker("census", testPolicy())
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+	gc := b.Geocoder(g)
+	ctx := context.Background()
+
+	// Two failed calls (two attempts each) reach the threshold.
+	for range 2 {
+		if _, err := gc.Geocode(ctx, "1 Main St", "", "", "90210"); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+	if st := b.Status(); st.State != StateOpen || st.RetryAt == nil {
+		t.Fatalf("expected open breaker, got %+v", st)
+	}
+
+	// While open, calls fail fast without reaching the provider.
+	calls := g.calls
+	if _, err := gc.Geocode(ctx, "1 Main St", "", "", "90210"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if g.calls != calls {
+		t.Errorf("provider called while open")
+	}
+
+	// After the cooldown a trial call goes through and closes the breaker.
+	now = now.Add(time.Minute)
+	result, err := gc.Geocode(ctx, "1 Main St", "", "", "90210")
+	if err != nil || result == nil {
+		t.Fatalf("expected trial call to succeed, got %+v, %v", result, err)
+	}
+	if st := b.Status(); st.State != StateClosed {
+		t.Errorf("expected closed breaker, got %+v", st)
+	}
+}
+
+func TestBreaker_FailedTrialReopens(t *testing.T) {
+	down := errors.New("connection refused")
+	g := &countingGeocoder{errs: []error{down, down, down, down, down}}
+	b := NewBreaker("census", Policy{MaxAttempts: 1, FailureThreshold: 1, Cooldown: time.Minute})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+	gc := b.Geocoder(g)
+
+	gc.Geocode(context.Background(), "1 Main St", "", "", "90210")
+	now = now.Add(time.Minute)
+	gc.Geocode(context.Background(), "1 Main St", "", "", "90210")
+
+	st := b.Status()
+	if st.State != StateOpen {
+		t.Fatalf("expected breaker to reopen, got %+v", st)
+	}
+	if !st.RetryAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("RetryAt = %v, want %v", st.RetryAt, now.Add(time.Minute))
+	}
+}
+
+func TestBreaker_AnsweredTrialResetsFailures(t *testing.T) {
+	down := errors.New("connection refused")
+	rejected := &StatusError{Provider: "census", Code: 400}
+	g := &countingGeocoder{errs: []error{down, down, down, down, rejected, down}}
+	b := NewBreaker("census", testPolicy())
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+	gc := b.Geocoder(g)
+	ctx := context.Background()
+
+	for range 2 {
+		gc.Geocode(ctx, "1 Main St", "", "", "90210")
+	}
+
+	// The trial call is rejected, which still shows the provider is up.
+	now = now.Add(time.Minute)
+	gc.Geocode(ctx, "1 Main St", "", "", "90210")
+	if st := b.Status(); st.State != StateClosed || st.ConsecutiveFailures != 0 {
+		t.Fatalf("expected a closed breaker with no failures, got %+v", st)
+	}
+
+	// One more failure is below the threshold again.
+	gc.Geocode(ctx, "1 Main St", "", "", "90210")
+	if st := b.Status(); st.State != StateClosed {
+		t.Errorf("one failure after recovery reopened the breaker: %+v", st)
+	}
+}
+
+func TestUnavailable(t *testing.T) {
+	open := fmt.Errorf("census: %w", ErrCircuitOpen)
+	failed := fmt.Errorf("nominatim: %w", errors.New("connection refused"))
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"breaker open", ErrCircuitOpen, true},
+		{"every breaker open", errors.Join(open, fmt.Errorf("nominatim: %w", ErrCircuitOpen)), true},
+		{"one provider failed", errors.Join(open, failed), false},
+		{"provider failed", failed, false},
+		{"no error", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unavailable(tt.err); got != tt.want {
+				t.Errorf("Unavailable = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreaker_BudgetBoundsRetries(t *testing.T) {
+	slow := geocodeFunc(func(ctx context.Context) (*Result, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	b := NewBreaker("census", Policy{
+		MaxAttempts:      5,
+		AttemptTimeout:   30 * time.Millisecond,
+		Budget:           50 * time.Millisecond,
+		Backoff:          10 * time.Millisecond,
+		FailureThreshold: 5,
+	})
+
+	start := time.Now()
+	_, err := b.Geocoder(slow).Geocode(context.Background(), "1 Main St", "", "", "90210")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("call took %v, budget not enforced", elapsed)
+	}
+}
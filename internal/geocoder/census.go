@@ -6,27 +6,23 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 )
 
-const censusBaseURL = "https://geocoding.geo.census.gov/geocoder/geographies/address"
-
-// Result holds the resolved FIPS codes from a geocoded address.
-type Result struct {
-	StateFIPS  string // 2-digit state FIPS, e.g. "06"
-	CountyFIPS string // 5-digit state+county FIPS, e.g. "06037"
-	PlaceFIPS  string // 7-digit state+place FIPS, e.g. "0644000" (empty if unincorporated)
-}
+const censusBaseURL = "https://geocoding.geo.census.gov/geocoder/geographies"
 
 // Client calls the US Census Bureau Geocoder API to resolve
 // street addresses into FIPS jurisdiction codes.
 type Client struct {
 	httpClient *http.Client
+	baseURL    string
 }
 
 func NewClient() *Client {
 	return &Client{
 		httpClient: &http.Client{Timeout: 10 * time.Second},
+		baseURL:    censusBaseURL,
 	}
 }
 
@@ -43,28 +39,62 @@ func (c *Client) Geocode(ctx context.Context, street, city, state, zip string) (
 		"format":    {"json"},
 	}
 
-	reqURL := censusBaseURL + "?" + params.Encode()
+	var body censusResponse
+	if err := c.get(ctx, "/address", params, &body); err != nil {
+		return nil, err
+	}
+
+	return parseResponse(body)
+}
+
+// Reverse resolves a latitude/longitude to FIPS codes using the Census
+// coordinates geographies endpoint. Returns nil (no error) if the point is
+// outside any US state, e.g. offshore.
+func (c *Client) Reverse(ctx context.Context, lat, lng float64) (*Result, error) {
+	params := url.Values{
+		"x":         {strconv.FormatFloat(lng, 'f', -1, 64)},
+		"y":         {strconv.FormatFloat(lat, 'f', -1, 64)},
+		"benchmark": {"Public_AR_Current"},
+		"vintage":   {"Current_Current"},
+		"format":    {"json"},
+	}
+
+	var body censusCoordinatesResponse
+	if err := c.get(ctx, "/coordinates", params, &body); err != nil {
+		return nil, err
+	}
+
+	result := parseGeographies(body.Result.Geographies)
+	if result != nil {
+		result.Latitude = lat
+		result.Longitude = lng
+	}
+	return result, nil
+}
+
+// get issues a GET against a Census geographies endpoint and decodes the
+// JSON body into dest.
+func (c *Client) get(ctx context.Context, path string, params url.Values, dest any) error {
+	reqURL := c.baseURL + path + "?" + params.Encode()
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("building request: %w", err)
+		return fmt.Errorf("building request: %w", err)
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("census geocoder request: %w", err)
+		return fmt.Errorf("census geocoder request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("census geocoder returned status %d", resp.StatusCode)
+		return &StatusError{Provider: "census geocoder", Code: resp.StatusCode}
 	}
 
-	var body censusResponse
-	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
-		return nil, fmt.Errorf("decoding census response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
+		return &decodeError{fmt.Errorf("decoding census response: %w", err)}
 	}
-
-	return parseResponse(body)
+	return nil
 }
 
 // parseResponse extracts FIPS codes from the Census Geocoder JSON response.
@@ -75,7 +105,21 @@ func parseResponse(body censusResponse) (*Result, error) {
 		return nil, nil
 	}
 
-	geo := matches[0].Geographies
+	// The Census geocoder orders candidates best-first.
+	best := matches[0]
+	result := parseGeographies(best.Geographies)
+	if result != nil {
+		result.Latitude = best.Coordinates.Y
+		result.Longitude = best.Coordinates.X
+		result.MatchedAddress = best.MatchedAddress
+		result.MatchCount = len(matches)
+	}
+	return result, nil
+}
+
+// parseGeographies extracts FIPS codes from a geographies block. Returns nil
+// if no state could be determined.
+func parseGeographies(geo geographies) *Result {
 	result := &Result{}
 
 	// State FIPS from Census Blocks (most reliable — every matched address has one).
@@ -84,7 +128,11 @@ func parseResponse(body censusResponse) (*Result, error) {
 		result.StateFIPS = block.State
 		if block.State != "" && block.County != "" {
 			result.CountyFIPS = block.State + block.County
+			if block.Tract != "" {
+				result.CensusTract = result.CountyFIPS + block.Tract
+			}
 		}
+		result.CensusBlock = block.GEOID
 	}
 
 	// Fall back to States/Counties arrays if blocks didn't have what we need.
@@ -100,11 +148,24 @@ func parseResponse(body censusResponse) (*Result, error) {
 		result.PlaceFIPS = geo.IncorporatedPlaces[0].GEOID
 	}
 
+	// County subdivisions cover every county; they matter where towns or
+	// townships levy tax, e.g. New England.
+	if len(geo.CountySubdivisions) > 0 {
+		result.SubdivisionFIPS = geo.CountySubdivisions[0].GEOID
+	}
+
+	// Tribal lands are either reservations or off-reservation trust lands.
+	if len(geo.Reservations) > 0 {
+		result.TribalFIPS = geo.Reservations[0].GEOID
+	} else if len(geo.TrustLands) > 0 {
+		result.TribalFIPS = geo.TrustLands[0].GEOID
+	}
+
 	if result.StateFIPS == "" {
-		return nil, nil
+		return nil
 	}
 
-	return result, nil
+	return result
 }
 
 // Census Geocoder API response types.
@@ -115,6 +176,12 @@ type censusResponse struct {
 	} `json:"result"`
 }
 
+type censusCoordinatesResponse struct {
+	Result struct {
+		Geographies geographies `json:"geographies"`
+	} `json:"result"`
+}
+
 type addressMatch struct {
 	MatchedAddress string      `json:"matchedAddress"`
 	Coordinates    coordinates `json:"coordinates"`
@@ -131,6 +198,11 @@ type geographies struct {
 	States             []geoEntity    `json:"States"`
 	Counties           []geoEntity    `json:"Counties"`
 	IncorporatedPlaces []geoEntity    `json:"Incorporated Places"`
+
+	// Minor civil divisions and tribal areas.
+	CountySubdivisions []geoEntity `json:"County Subdivisions"`
+	Reservations       []geoEntity `json:"Federal American Indian Reservations"`
+	TrustLands         []geoEntity `json:"Off-Reservation Trust Lands"`
 }
 
 type censusBlock struct {
@@ -1,6 +1,10 @@
 package geocoder
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 )
 
@@ -38,6 +42,18 @@ func TestParseResponse_FullMatch(t *testing.T) {
 	if result.PlaceFIPS != "0603744000" {
 		t.Errorf("PlaceFIPS = %q, want %q", result.PlaceFIPS, "0603744000")
 	}
+	if result.MatchedAddress != "123 MAIN ST, BEVERLY HILLS, CA, 90210" {
+		t.Errorf("MatchedAddress = %q", result.MatchedAddress)
+	}
+	if result.CensusTract != "06037701002" {
+		t.Errorf("CensusTract = %q, want %q", result.CensusTract, "06037701002")
+	}
+	if result.CensusBlock != "060370701002014" {
+		t.Errorf("CensusBlock = %q, want %q", result.CensusBlock, "060370701002014")
+	}
+	if result.MatchCount != 1 {
+		t.Errorf("MatchCount = %d, want 1", result.MatchCount)
+	}
 }
 
 func TestParseResponse_NoAddressMatches(t *testing.T) {
@@ -163,3 +179,118 @@ func TestParseResponse_EmptyGeographies(t *testing.T) {
 		t.Fatalf("expected nil for empty geographies, got %+v", result)
 	}
 }
+
+func TestParseResponse_SubdivisionAndTribal(t *testing.T) {
+	resp := censusResponse{}
+	resp.Result.AddressMatches = []addressMatch{
+		{
+			MatchedAddress: "1 MAIN ST, LEXINGTON, MA, 02420",
+			Geographies: geographies{
+				States:             []geoEntity{{GEOID: "25"}},
+				Counties:           []geoEntity{{GEOID: "25017"}},
+				CountySubdivisions: []geoEntity{{GEOID: "2501735215", Name: "Lexington town"}},
+				TrustLands:         []geoEntity{{GEOID: "9999", Name: "Example Trust Land"}},
+			},
+		},
+	}
+
+	result, err := parseResponse(resp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.SubdivisionFIPS != "2501735215" {
+		t.Errorf("SubdivisionFIPS = %q, want %q", result.SubdivisionFIPS, "2501735215")
+	}
+	if result.TribalFIPS != "9999" {
+		t.Errorf("TribalFIPS = %q, want %q", result.TribalFIPS, "9999")
+	}
+	if result.PlaceFIPS != "" {
+		t.Errorf("PlaceFIPS = %q, want empty", result.PlaceFIPS)
+	}
+}
+
+// newFakeCensus starts a local stand-in for the Census geographies API that
+// serves the given JSON bodies keyed by endpoint path.
+func newFakeCensus(t *testing.T, bodies map[string]string) (*Client, *[]url.Values) {
+	t.Helper()
+
+	var requests []url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := bodies[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		requests = append(requests, r.URL.Query())
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient()
+	c.baseURL = srv.URL
+	return c, &requests
+}
+
+func TestReverse_ResolvesPoint(t *testing.T) {
+	c, requests := newFakeCensus(t, map[string]string{
+		"/coordinates": `{"result":{"geographies":{
+			"Census Blocks":[{"STATE":"06","COUNTY":"037","TRACT":"701002","BLOCK":"2014"}],
+			"Incorporated Places":[{"GEOID":"0603744000","NAME":"Beverly Hills"}]
+		}}}`,
+	})
+
+	result, err := c.Reverse(context.Background(), 34.0736, -118.4004)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result == nil {
+		t.Fatal("expected non-nil result")
+	}
+	if result.StateFIPS != "06" || result.CountyFIPS != "06037" || result.PlaceFIPS != "0603744000" {
+		t.Errorf("got %+v, want state 06, county 06037, place 0603744000", result)
+	}
+
+	q := (*requests)[0]
+	if q.Get("x") != "-118.4004" || q.Get("y") != "34.0736" {
+		t.Errorf("expected x=lng and y=lat, got x=%s y=%s", q.Get("x"), q.Get("y"))
+	}
+}
+
+func TestReverse_OutsideUS(t *testing.T) {
+	c, _ := newFakeCensus(t, map[string]string{
+		"/coordinates": `{"result":{"geographies":{}}}`,
+	})
+
+	result, err := c.Reverse(context.Background(), 0, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != nil {
+		t.Fatalf("expected nil result for a point outside the US, got %+v", result)
+	}
+}
+
+func TestGeocode_UsesAddressEndpoint(t *testing.T) {
+	c, _ := newFakeCensus(t, map[string]string{
+		"/address": `{"result":{"addressMatches":[{"geographies":{
+			"States":[{"GEOID":"36"}],"Counties":[{"GEOID":"36061"}]
+		}}]}}`,
+	})
+
+	result, err := c.Geocode(context.Background(), "350 5th Ave", "New York", "NY", "10118")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result == nil || result.CountyFIPS != "36061" {
+		t.Fatalf("expected county 36061, got %+v", result)
+	}
+}
+
+func TestReverse_ErrorStatus(t *testing.T) {
+	c, _ := newFakeCensus(t, map[string]string{})
+
+	if _, err := c.Reverse(context.Background(), 34.07, -118.4); err == nil {
+		t.Fatal("expected error for non-200 response")
+	}
+}
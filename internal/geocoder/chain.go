@@ -0,0 +1,62 @@
+package geocoder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Provider is one geocoder in a Chain.
+type Provider struct {
+	Name     string
+	Geocoder Geocoder
+	// Timeout bounds a single call to this provider. Zero means the caller's
+	// deadline alone applies.
+	Timeout time.Duration
+}
+
+// Chain tries providers in order and returns the first match. A provider
+// that errors or times out is skipped; one that reports no match is also
+// skipped, since providers differ in coverage.
+type Chain struct {
+	providers []Provider
+}
+
+func NewChain(providers ...Provider) *Chain {
+	return &Chain{providers: providers}
+}
+
+// Geocode returns the first provider's match. If no provider matched, it
+// returns nil with no error only when every provider answered cleanly;
+// otherwise it returns the joined errors of those that failed, since one of
+// them might have matched.
+func (c *Chain) Geocode(ctx context.Context, street, city, state, zip string) (*Result, error) {
+	var errs []error
+
+	for _, p := range c.providers {
+		result, err := c.try(ctx, p, street, city, state, zip)
+		if err != nil {
+			slog.Warn("geocoder provider failed", "provider", p.Name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+			continue
+		}
+		if result != nil {
+			if result.Provider == "" {
+				result.Provider = p.Name
+			}
+			return result, nil
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (c *Chain) try(ctx context.Context, p Provider, street, city, state, zip string) (*Result, error) {
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+	return p.Geocoder.Geocode(ctx, street, city, state, zip)
+}
@@ -0,0 +1,178 @@
+package geocoder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// geocodeFunc adapts a function to the Geocoder interface.
+type geocodeFunc func(ctx context.Context) (*Result, error)
+
+func (f geocodeFunc) Geocode(ctx context.Context, street, city, state, zip string) (*Result, error) {
+	return f(ctx)
+}
+
+func fixed(result *Result, err error) Geocoder {
+	return geocodeFunc(func(context.Context) (*Result, error) { return result, err })
+}
+
+func TestChain_FirstMatchWins(t *testing.T) {
+	c := NewChain(
+		Provider{Name: "a", Geocoder: fixed(&Result{StateFIPS: "06"}, nil)},
+		Provider{Name: "b", Geocoder: fixed(&Result{StateFIPS: "36"}, nil)},
+	)
+
+	result, err := c.Geocode(context.Background(), "1 Main St", "", "", "90210")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result == nil || result.StateFIPS != "06" {
+		t.Fatalf("expected first provider's result, got %+v", result)
+	}
+	if result.Provider != "a" {
+		t.Errorf("Provider = %q, want %q", result.Provider, "a")
+	}
+}
+
+func TestChain_FallsBackOnErrorAndNoMatch(t *testing.T) {
+	c := NewChain(
+		Provider{Name: "down", Geocoder: fixed(nil, errors.New("connection refused"))},
+		Provider{Name: "nomatch", Geocoder: fixed(nil, nil)},
+		Provider{Name: "ok", Geocoder: fixed(&Result{StateFIPS: "17"}, nil)},
+	)
+
+	result, err := c.Geocode(context.Background(), "1 Main St", "", "", "60601")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result == nil || result.StateFIPS != "17" {
+		t.Fatalf("expected fallback provider's result, got %+v", result)
+	}
+}
+
+func TestChain_PerProviderTimeout(t *testing.T) {
+	slow := geocodeFunc(func(ctx context.Context) (*Result, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	c := NewChain(
+		Provider{Name: "slow", Geocoder: slow, Timeout: 10 * time.Millisecond},
+		Provider{Name: "fast", Geocoder: fixed(&Result{StateFIPS: "48"}, nil)},
+	)
+
+	start := time.Now()
+	result, err := c.Geocode(context.Background(), "1 Main St", "", "", "77001")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result == nil || result.StateFIPS != "48" {
+		t.Fatalf("expected fast provider's result, got %+v", result)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("slow provider was not cut off by its timeout (took %s)", elapsed)
+	}
+}
+
+func TestChain_AllFailed(t *testing.T) {
+	c := NewChain(
+		Provider{Name: "a", Geocoder: fixed(nil, errors.New("boom"))},
+		Provider{Name: "b", Geocoder: fixed(nil, errors.New("bang"))},
+	)
+
+	if _, err := c.Geocode(context.Background(), "1 Main St", "", "", "90210"); err == nil {
+		t.Fatal("expected error when every provider fails")
+	}
+}
+
+func TestChain_NoMatchIsNotAnError(t *testing.T) {
+	c := NewChain(
+		Provider{Name: "a", Geocoder: fixed(nil, nil)},
+		Provider{Name: "b", Geocoder: fixed(nil, nil)},
+	)
+
+	result, err := c.Geocode(context.Background(), "1 Main St", "", "", "90210")
+	if err != nil || result != nil {
+		t.Fatalf("expected (nil, nil) when every provider reports no match, got (%+v, %v)", result, err)
+	}
+}
+
+func TestChain_NoMatchWithFailureIsAnError(t *testing.T) {
+	c := NewChain(
+		Provider{Name: "a", Geocoder: fixed(nil, errors.New("boom"))},
+		Provider{Name: "b", Geocoder: fixed(nil, nil)},
+	)
+
+	result, err := c.Geocode(context.Background(), "1 Main St", "", "", "90210")
+	if err == nil || result != nil {
+		t.Fatalf("expected an error when a provider failed and none matched, got (%+v, %v)", result, err)
+	}
+	if Unavailable(err) {
+		t.Error("a failed provider was reported as unavailable")
+	}
+}
+
+// reverseFunc adapts a function to the Reverser interface.
+type reverseFunc func(lat, lng float64) (*Result, error)
+
+func (f reverseFunc) Reverse(_ context.Context, lat, lng float64) (*Result, error) {
+	return f(lat, lng)
+}
+
+func TestWithBoundaries(t *testing.T) {
+	local := reverseFunc(func(lat, lng float64) (*Result, error) {
+		if lat == 34.07 && lng == -118.4 {
+			return &Result{StateFIPS: "06", CountyFIPS: "06037", PlaceFIPS: "0644000", Latitude: lat, Longitude: lng}, nil
+		}
+		return nil, nil
+	})
+
+	t.Run("replaces codes when covered", func(t *testing.T) {
+		g := WithBoundaries(fixed(&Result{StateFIPS: "06", Latitude: 34.07, Longitude: -118.4, MatchedAddress: "1 MAIN ST", MatchCount: 2}, nil), local)
+		result, _ := g.Geocode(context.Background(), "1 Main St", "", "", "90001")
+		if result == nil || result.PlaceFIPS != "0644000" {
+			t.Fatalf("expected local place FIPS, got %+v", result)
+		}
+		if result.MatchedAddress != "1 MAIN ST" || result.MatchCount != 2 {
+			t.Errorf("expected match details to be kept, got %+v", result)
+		}
+	})
+
+	t.Run("keeps codes when not covered", func(t *testing.T) {
+		g := WithBoundaries(fixed(&Result{StateFIPS: "36", Latitude: 40.7, Longitude: -74}, nil), local)
+		result, _ := g.Geocode(context.Background(), "1 Main St", "", "", "10001")
+		if result == nil || result.StateFIPS != "36" {
+			t.Fatalf("expected geocoder's own result, got %+v", result)
+		}
+	})
+
+	t.Run("keeps codes for layers the index lacks", func(t *testing.T) {
+		counties := reverseFunc(func(lat, lng float64) (*Result, error) {
+			return &Result{StateFIPS: "06", CountyFIPS: "06059", Layers: []string{LayerCounty}}, nil
+		})
+		census := &Result{
+			StateFIPS: "06", CountyFIPS: "06037", PlaceFIPS: "0644000", SubdivisionFIPS: "0603791400",
+			SpecialDistricts: []string{"06037SD01"}, Latitude: 34.07, Longitude: -118.4,
+		}
+		g := WithBoundaries(fixed(census, nil), counties)
+		result, _ := g.Geocode(context.Background(), "1 Main St", "", "", "90001")
+		if result == nil || result.CountyFIPS != "06059" {
+			t.Fatalf("expected local county FIPS, got %+v", result)
+		}
+		if result.PlaceFIPS != "0644000" || result.SubdivisionFIPS != "0603791400" || len(result.SpecialDistricts) != 1 {
+			t.Errorf("expected codes for uncovered layers to be kept, got %+v", result)
+		}
+	})
+
+	t.Run("clears codes for layers the index holds", func(t *testing.T) {
+		places := reverseFunc(func(lat, lng float64) (*Result, error) {
+			return &Result{StateFIPS: "06", CountyFIPS: "06037", Layers: []string{LayerCounty, LayerPlace}}, nil
+		})
+		g := WithBoundaries(fixed(&Result{StateFIPS: "06", PlaceFIPS: "0644000", Latitude: 34.07, Longitude: -118.4}, nil), places)
+		result, _ := g.Geocode(context.Background(), "1 Main St", "", "", "90001")
+		if result == nil || result.PlaceFIPS != "" {
+			t.Fatalf("expected the index to rule out a place, got %+v", result)
+		}
+	})
+}
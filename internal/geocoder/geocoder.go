@@ -0,0 +1,123 @@
+package geocoder
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Boundary layers. Each one fills a single Result field.
+const (
+	LayerState             = "state"
+	LayerCounty            = "county"
+	LayerPlace             = "place"
+	LayerCountySubdivision = "county_subdivision"
+	LayerTribalArea        = "tribal_area"
+	LayerSpecialDistrict   = "special_district"
+)
+
+// Result holds the resolved FIPS codes from a geocoded address.
+type Result struct {
+	StateFIPS  string // 2-digit state FIPS, e.g. "06"
+	CountyFIPS string // 5-digit state+county FIPS, e.g. "06037"
+	PlaceFIPS  string // 7-digit state+place FIPS, e.g. "0644000" (empty if unincorporated)
+
+	// SubdivisionFIPS is the 10-digit state+county+subdivision GEOID of the
+	// county subdivision (town, township, MCD), e.g. "2502507000".
+	SubdivisionFIPS string
+	// TribalFIPS is the 4-digit GEOID of the American Indian reservation or
+	// trust land, if any.
+	TribalFIPS string
+
+	// SpecialDistricts holds the FIPS codes of special districts whose
+	// boundaries contain the point. Only local boundaries report these.
+	SpecialDistricts []string
+
+	// Layers lists the boundary layers a local index derived the codes
+	// from. An empty field whose layer isn't listed is unknown rather than
+	// absent. Remote providers leave it empty.
+	Layers []string
+
+	// Coordinates of the match. Zero when the provider didn't return any.
+	Latitude  float64
+	Longitude float64
+
+	// Match details, when the provider reports them.
+	MatchedAddress string // provider's standardized form of the address
+	CensusTract    string // 11-digit state+county+tract GEOID
+	CensusBlock    string // 15-digit block GEOID
+	MatchCount     int    // number of candidate matches; >1 means the address was ambiguous
+	Provider       string // name of the chain provider that produced the match
+}
+
+// HasLayer reports whether the codes for layer were derived from local
+// boundaries.
+func (r *Result) HasLayer(layer string) bool {
+	return slices.Contains(r.Layers, layer)
+}
+
+// Geocoder resolves a street address to FIPS codes. Implementations return
+// nil (no error) when the address could not be matched.
+type Geocoder interface {
+	Geocode(ctx context.Context, street, city, state, zip string) (*Result, error)
+}
+
+// Reverser resolves coordinates to FIPS codes. Implementations return nil
+// (no error) when the point is outside every known jurisdiction.
+type Reverser interface {
+	Reverse(ctx context.Context, lat, lng float64) (*Result, error)
+}
+
+// WithBoundaries wraps g so each match's FIPS codes are re-derived from its
+// coordinates using rv, typically a local boundary index. The wrapped
+// geocoder's own codes are kept when rv has no coverage for the point, and
+// for each layer rv doesn't hold.
+func WithBoundaries(g Geocoder, rv Reverser) Geocoder {
+	return &relocated{next: g, reverser: rv}
+}
+
+type relocated struct {
+	next     Geocoder
+	reverser Reverser
+}
+
+func (r *relocated) Geocode(ctx context.Context, street, city, state, zip string) (*Result, error) {
+	result, err := r.next.Geocode(ctx, street, city, state, zip)
+	if err != nil || result == nil || (result.Latitude == 0 && result.Longitude == 0) {
+		return result, err
+	}
+
+	local, err := r.reverser.Reverse(ctx, result.Latitude, result.Longitude)
+	if err != nil || local == nil {
+		return result, nil
+	}
+
+	// Keep the match details; only the jurisdiction codes are replaced, and
+	// only where the index found one or holds the layer to rule one out.
+	relocated := *result
+	replace := func(dst *string, code, layer string) {
+		if code != "" || local.HasLayer(layer) {
+			*dst = code
+		}
+	}
+	replace(&relocated.StateFIPS, local.StateFIPS, LayerState)
+	replace(&relocated.CountyFIPS, local.CountyFIPS, LayerCounty)
+	replace(&relocated.PlaceFIPS, local.PlaceFIPS, LayerPlace)
+	replace(&relocated.SubdivisionFIPS, local.SubdivisionFIPS, LayerCountySubdivision)
+	replace(&relocated.TribalFIPS, local.TribalFIPS, LayerTribalArea)
+	if len(local.SpecialDistricts) > 0 || local.HasLayer(LayerSpecialDistrict) {
+		relocated.SpecialDistricts = local.SpecialDistricts
+	}
+	relocated.Layers = local.Layers
+	return &relocated, nil
+}
+
+// StatusError is returned when a provider answers with a non-200 status.
+type StatusError struct {
+	Provider string
+	Code     int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s returned status %d", e.Provider, e.Code)
+}
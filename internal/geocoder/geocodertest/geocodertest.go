@@ -0,0 +1,55 @@
+// Package geocodertest provides in-memory geocoders for tests.
+package geocodertest
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/prashkn/sales-tax-api/internal/geocoder"
+)
+
+// Geocoder matches addresses by street, ignoring case. Streets not in
+// Results are no match. Err, if set, is returned for every call instead.
+type Geocoder struct {
+	Results map[string]*geocoder.Result
+	Err     error
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (g *Geocoder) Geocode(_ context.Context, street, _, _, _ string) (*geocoder.Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.calls++
+	if g.Err != nil {
+		return nil, g.Err
+	}
+	for s, r := range g.Results {
+		if strings.EqualFold(s, street) {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+// Calls returns how many addresses have been geocoded.
+func (g *Geocoder) Calls() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.calls
+}
+
+// Reverser returns Result for every point, or Err if set.
+type Reverser struct {
+	Result *geocoder.Result
+	Err    error
+}
+
+func (r *Reverser) Reverse(context.Context, float64, float64) (*geocoder.Result, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	return r.Result, nil
+}
@@ -0,0 +1,95 @@
+package geocoder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// NominatimClient geocodes against a self-hosted Nominatim-compatible
+// search API. Such services return coordinates only, so FIPS codes come
+// from a Reverser (the Census coordinates endpoint or a boundary index).
+type NominatimClient struct {
+	httpClient *http.Client
+	baseURL    string
+	reverser   Reverser
+}
+
+func NewNominatimClient(baseURL string, rv Reverser) *NominatimClient {
+	return &NominatimClient{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		baseURL:    baseURL,
+		reverser:   rv,
+	}
+}
+
+// Geocode looks up the address with a structured search and reverses the
+// best match's coordinates to FIPS codes. Up to five candidates are
+// requested so MatchCount can flag ambiguous addresses. Returns nil (no
+// error) if the address could not be matched.
+func (c *NominatimClient) Geocode(ctx context.Context, street, city, state, zip string) (*Result, error) {
+	params := url.Values{
+		"street":       {street},
+		"city":         {city},
+		"state":        {state},
+		"postalcode":   {zip},
+		"countrycodes": {"us"},
+		"format":       {"jsonv2"},
+		"limit":        {"5"},
+	}
+
+	reqURL := c.baseURL + "/search?" + params.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("nominatim request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{Provider: "nominatim", Code: resp.StatusCode}
+	}
+
+	var places []nominatimPlace
+	if err := json.NewDecoder(resp.Body).Decode(&places); err != nil {
+		return nil, &decodeError{fmt.Errorf("decoding nominatim response: %w", err)}
+	}
+	if len(places) == 0 {
+		return nil, nil
+	}
+
+	lat, err := strconv.ParseFloat(places[0].Lat, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parsing nominatim latitude: %w", err)
+	}
+	lng, err := strconv.ParseFloat(places[0].Lon, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parsing nominatim longitude: %w", err)
+	}
+
+	result, err := c.reverser.Reverse(ctx, lat, lng)
+	if err != nil || result == nil {
+		return result, err
+	}
+	result.Latitude = lat
+	result.Longitude = lng
+	result.MatchedAddress = places[0].DisplayName
+	result.MatchCount = len(places)
+	return result, nil
+}
+
+// Nominatim search API response types. Coordinates are JSON strings.
+
+type nominatimPlace struct {
+	Lat         string `json:"lat"`
+	Lon         string `json:"lon"`
+	DisplayName string `json:"display_name"`
+}
@@ -0,0 +1,60 @@
+package geocoder
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNominatim_ReversesBestMatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("postalcode") != "90210" {
+			t.Errorf("expected postalcode=90210, got %q", r.URL.Query().Get("postalcode"))
+		}
+		w.Write([]byte(`[{"lat":"34.0736","lon":"-118.4004","display_name":"Beverly Hills"}]`))
+	}))
+	defer srv.Close()
+
+	var gotLat, gotLng float64
+	rv := reverseFunc(func(lat, lng float64) (*Result, error) {
+		gotLat, gotLng = lat, lng
+		return &Result{StateFIPS: "06", CountyFIPS: "06037"}, nil
+	})
+
+	c := NewNominatimClient(srv.URL, rv)
+	result, err := c.Geocode(context.Background(), "9400 Wilshire Blvd", "Beverly Hills", "CA", "90210")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result == nil || result.CountyFIPS != "06037" {
+		t.Fatalf("expected reversed result, got %+v", result)
+	}
+	if gotLat != 34.0736 || gotLng != -118.4004 {
+		t.Errorf("reversed (%v, %v), want (34.0736, -118.4004)", gotLat, gotLng)
+	}
+	if result.MatchedAddress != "Beverly Hills" || result.MatchCount != 1 {
+		t.Errorf("expected match details, got %+v", result)
+	}
+}
+
+func TestNominatim_NoMatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	rv := reverseFunc(func(lat, lng float64) (*Result, error) {
+		t.Fatal("reverser should not be called without a match")
+		return nil, nil
+	})
+
+	result, err := NewNominatimClient(srv.URL, rv).Geocode(context.Background(), "nowhere", "", "", "00000")
+	if err != nil || result != nil {
+		t.Fatalf("expected (nil, nil), got (%+v, %v)", result, err)
+	}
+}
@@ -1,23 +1,35 @@
 package handler
 
 import (
+	"context"
 	"math"
 	"net/http"
 	"time"
 
 	"github.com/prashkn/sales-tax-api/internal/cache"
+	"github.com/prashkn/sales-tax-api/internal/geocoder"
 	"github.com/prashkn/sales-tax-api/internal/service"
 	"github.com/prashkn/sales-tax-api/internal/store"
 )
 
+// HealthCache is the cache as the health check sees it. *cache.Cache
+// implements it.
+type HealthCache interface {
+	Ping(ctx context.Context) error
+	Stats() cache.Stats
+	Version() string
+	Backend() string
+}
+
 type HealthHandler struct {
-	store      *store.Store
-	cache      *cache.Cache
+	store      store.Store
+	cache      HealthCache
 	taxService *service.TaxService
+	breakers   []*geocoder.Breaker
 }
 
-func NewHealthHandler(s *store.Store, c *cache.Cache, ts *service.TaxService) *HealthHandler {
-	return &HealthHandler{store: s, cache: c, taxService: ts}
+func NewHealthHandler(s store.Store, c HealthCache, ts *service.TaxService, breakers ...*geocoder.Breaker) *HealthHandler {
+	return &HealthHandler{store: s, cache: c, taxService: ts, breakers: breakers}
 }
 
 func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
@@ -31,16 +43,23 @@ func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
 		status = http.StatusServiceUnavailable
 	}
 
-	redisStatus := "ok"
+	// The cache doesn't fail the check: lookups skip it while it's down.
+	cacheStatus := "ok"
 	if err := h.cache.Ping(ctx); err != nil {
-		redisStatus = "error: " + err.Error()
-		status = http.StatusServiceUnavailable
+		cacheStatus = "error: " + err.Error()
 	}
 
 	resp := map[string]any{
-		"status":   "healthy",
-		"database": dbStatus,
-		"redis":    redisStatus,
+		"status":        "healthy",
+		"database":      dbStatus,
+		"cache_backend": h.cache.Backend(),
+		"cache_status":  cacheStatus,
+		"cache":         h.cache.Stats(),
+	}
+	// Deprecated: kept for clients of the Redis-only health check; use
+	// cache_status.
+	if h.cache.Backend() == "redis" {
+		resp["redis"] = cacheStatus
 	}
 
 	if status != http.StatusOK {
@@ -54,6 +73,7 @@ func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
 			"last_updated": df.LastUpdated.Format(time.DateOnly),
 			"age_days":     ageDays,
 			"record_count": df.RecordCount,
+			"version":      h.cache.Version(),
 		}
 		// Warn if data is older than 100 days (quarterly updates + buffer).
 		if ageDays > 100 {
@@ -62,5 +82,23 @@ func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
 		resp["data"] = data
 	}
 
+	// Read replicas. A replica that's down doesn't fail the check: lookups
+	// fall back to the primary.
+	if rs, ok := h.store.(interface{ Replicas() []store.ReplicaStatus }); ok {
+		if replicas := rs.Replicas(); len(replicas) > 0 {
+			resp["replicas"] = replicas
+		}
+	}
+
+	// Geocoder breakers. An open breaker doesn't fail the check: address
+	// lookups still answer from the ZIP while it's open.
+	if len(h.breakers) > 0 {
+		geocoders := make([]geocoder.BreakerStatus, 0, len(h.breakers))
+		for _, b := range h.breakers {
+			geocoders = append(geocoders, b.Status())
+		}
+		resp["geocoders"] = geocoders
+	}
+
 	writeJSON(w, status, resp)
 }
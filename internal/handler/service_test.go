@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/prashkn/sales-tax-api/internal/cache"
+	"github.com/prashkn/sales-tax-api/internal/geocoder"
+	"github.com/prashkn/sales-tax-api/internal/geocoder/geocodertest"
+	"github.com/prashkn/sales-tax-api/internal/service"
+	"github.com/prashkn/sales-tax-api/internal/store/storetest"
+)
+
+// These tests run requests through a real service over the sample data.
+
+func newTestRouter(t *testing.T) (chi.Router, *storetest.Fake) {
+	t.Helper()
+	s := storetest.New(t, storetest.Sample())
+	c := cache.New(cache.NewMemory(100), cache.Options{TTL: time.Hour})
+	ts := service.NewTaxService(s, c, &geocodertest.Geocoder{}, &geocodertest.Reverser{})
+
+	h := NewTaxHandler(ts)
+	r := chi.NewRouter()
+	r.Get("/v1/health", NewHealthHandler(s, c, ts).Health)
+	r.Get("/v1/tax/zip/{zip_code}", h.LookupByZIP)
+	r.Post("/v1/tax/calculate", h.Calculate)
+	r.Post("/v1/tax/bulk", h.Bulk)
+	return r, s
+}
+
+func TestLookupByZIP_Seeded(t *testing.T) {
+	r, _ := newTestRouter(t)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/v1/tax/zip/90210", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body)
+	}
+	var resp service.TaxResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.ZIPCode != "90210" || len(resp.Jurisdictions) != 4 {
+		t.Errorf("got zip %q with %d jurisdictions, want 90210 with 4", resp.ZIPCode, len(resp.Jurisdictions))
+	}
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/v1/tax/zip/00000", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("unknown zip: expected 404, got %d", rr.Code)
+	}
+}
+
+func TestCalculate_Seeded(t *testing.T) {
+	r, _ := newTestRouter(t)
+
+	body := `{"zip_code":"90001","amount":50}`
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("POST", "/v1/tax/calculate", strings.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body)
+	}
+	var resp service.CalculateResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if !approx(resp.TaxAmount, 4) || !approx(resp.Total, 54) {
+		t.Errorf("got tax %v total %v, want 4 and 54", resp.TaxAmount, resp.Total)
+	}
+}
+
+func TestHealth_StoreDown(t *testing.T) {
+	r, s := newTestRouter(t)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/v1/health", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body)
+	}
+
+	s.SetErr(errors.New("connection refused"))
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/v1/health", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("store down: expected 503, got %d", rr.Code)
+	}
+}
+
+// redisBacked reports the Redis backend over an in-memory cache.
+type redisBacked struct{ *cache.Cache }
+
+func (redisBacked) Backend() string { return "redis" }
+
+func TestHealth_ReportsCacheBackend(t *testing.T) {
+	s := storetest.New(t, storetest.Sample())
+	c := cache.New(cache.NewMemory(100), cache.Options{TTL: time.Hour})
+	ts := service.NewTaxService(s, c, &geocodertest.Geocoder{}, &geocodertest.Reverser{})
+
+	health := func(hc HealthCache) map[string]any {
+		rr := httptest.NewRecorder()
+		NewHealthHandler(s, hc, ts).Health(rr, httptest.NewRequest("GET", "/v1/health", nil))
+		var resp map[string]any
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := health(c)
+	if resp["cache_backend"] != "memory" || resp["cache_status"] != "ok" {
+		t.Errorf("cache_backend = %v, cache_status = %v; want memory, ok", resp["cache_backend"], resp["cache_status"])
+	}
+	if _, ok := resp["redis"]; ok {
+		t.Error("redis reported for the memory backend")
+	}
+
+	// The deprecated field stays for Redis-backed instances.
+	if resp := health(redisBacked{c}); resp["redis"] != "ok" {
+		t.Errorf("redis = %v, want ok", resp["redis"])
+	}
+}
+
+func TestLookupErrors_StoreDown(t *testing.T) {
+	r, s := newTestRouter(t)
+	s.SetErr(errors.New("connection refused"))
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/v1/tax/zip/90210", nil))
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("zip lookup: expected 500, got %d", rr.Code)
+	}
+	if strings.Contains(rr.Body.String(), "connection refused") {
+		t.Errorf("zip lookup leaked the store error: %s", rr.Body)
+	}
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("POST", "/v1/tax/bulk", strings.NewReader(`{"zip_codes":["90210"]}`)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("bulk: expected 200, got %d: %s", rr.Code, rr.Body)
+	}
+	var bulk struct {
+		Results map[string]struct {
+			Status int `json:"status"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &bulk); err != nil {
+		t.Fatal(err)
+	}
+	if got := bulk.Results["90210"].Status; got != http.StatusInternalServerError {
+		t.Errorf("bulk: got status %d for 90210, want 500", got)
+	}
+}
+
+func TestLookupByPoint_GeocoderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"provider failed", errors.New("census: 500"), http.StatusBadGateway},
+		{"breaker open", fmt.Errorf("census: %w", geocoder.ErrCircuitOpen), http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := storetest.New(t, storetest.Sample())
+			c := cache.New(cache.NewMemory(100), cache.Options{TTL: time.Hour})
+			ts := service.NewTaxService(s, c, &geocodertest.Geocoder{}, &geocodertest.Reverser{Err: tt.err})
+
+			rr := httptest.NewRecorder()
+			NewTaxHandler(ts).LookupByPoint(rr, httptest.NewRequest("GET", "/v1/tax/point?lat=34.07&lng=-118.40", nil))
+			if rr.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, rr.Code, rr.Body)
+			}
+		})
+	}
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -2,14 +2,23 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
 	"net/http"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/prashkn/sales-tax-api/internal/geocoder"
 	"github.com/prashkn/sales-tax-api/internal/service"
+	"github.com/prashkn/sales-tax-api/internal/store"
 )
 
-var zipRegex = regexp.MustCompile(`^\d{5}$`)
+// zipRegex accepts a 5-digit ZIP or a ZIP+4, with or without the hyphen.
+var zipRegex = regexp.MustCompile(`^\d{5}(-?\d{4})?$`)
 
 type TaxHandler struct {
 	svc *service.TaxService
@@ -23,13 +32,13 @@ func NewTaxHandler(svc *service.TaxService) *TaxHandler {
 func (h *TaxHandler) LookupByZIP(w http.ResponseWriter, r *http.Request) {
 	zip := chi.URLParam(r, "zip_code")
 	if !zipRegex.MatchString(zip) {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid zip code, must be 5 digits"})
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid zip code, must be 5 digits or ZIP+4"})
 		return
 	}
 
 	resp, err := h.svc.LookupByZIP(r.Context(), zip)
 	if err != nil {
-		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		writeLookupError(w, r, err)
 		return
 	}
 
@@ -47,10 +56,44 @@ func (h *TaxHandler) LookupByAddress(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "zip query parameter is required"})
 		return
 	}
+	if !zipRegex.MatchString(zip) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid zip code, must be 5 digits or ZIP+4"})
+		return
+	}
 
 	resp, err := h.svc.LookupByAddress(r.Context(), street, city, state, zip)
 	if err != nil {
-		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		var mismatch *service.MismatchError
+		if errors.As(err, &mismatch) {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
+				"error":      err.Error(),
+				"mismatches": mismatch.Mismatches,
+			})
+			return
+		}
+		writeLookupError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GET /v1/tax/point?lat=...&lng=...
+func (h *TaxHandler) LookupByPoint(w http.ResponseWriter, r *http.Request) {
+	lat, err := strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
+	if err != nil || math.IsNaN(lat) || lat < -90 || lat > 90 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "lat must be a number between -90 and 90"})
+		return
+	}
+	lng, err := strconv.ParseFloat(r.URL.Query().Get("lng"), 64)
+	if err != nil || math.IsNaN(lng) || lng < -180 || lng > 180 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "lng must be a number between -180 and 180"})
+		return
+	}
+
+	resp, err := h.svc.LookupByPoint(r.Context(), lat, lng)
+	if err != nil {
+		writeLookupError(w, r, err)
 		return
 	}
 
@@ -60,8 +103,49 @@ func (h *TaxHandler) LookupByAddress(w http.ResponseWriter, r *http.Request) {
 // POST /v1/tax/calculate
 func (h *TaxHandler) Calculate(w http.ResponseWriter, r *http.Request) {
 	var req struct {
+		ZIPCode         string  `json:"zip_code"`
+		Amount          float64 `json:"amount"`
+		TransactionType string  `json:"transaction_type"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if req.TransactionType == "" {
+		req.TransactionType = store.RateGeneral
+	}
+
+	if !zipRegex.MatchString(req.ZIPCode) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid zip code"})
+		return
+	}
+	if req.Amount <= 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "amount must be positive"})
+		return
+	}
+	if !slices.Contains(store.RateTypes, req.TransactionType) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "transaction_type must be one of " + strings.Join(store.RateTypes, ", ")})
+		return
+	}
+
+	resp, err := h.svc.Calculate(r.Context(), req.ZIPCode, req.Amount, req.TransactionType)
+	if err != nil {
+		writeLookupError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// POST /v1/tax/use-tax
+func (h *TaxHandler) UseTax(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Street  string  `json:"street"`
+		City    string  `json:"city"`
+		State   string  `json:"state"`
 		ZIPCode string  `json:"zip_code"`
 		Amount  float64 `json:"amount"`
+		TaxPaid float64 `json:"tax_paid"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
@@ -76,10 +160,22 @@ func (h *TaxHandler) Calculate(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "amount must be positive"})
 		return
 	}
+	if req.TaxPaid < 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "tax_paid must not be negative"})
+		return
+	}
 
-	resp, err := h.svc.Calculate(r.Context(), req.ZIPCode, req.Amount)
+	resp, err := h.svc.UseTax(r.Context(), req.Street, req.City, req.State, req.ZIPCode, req.Amount, req.TaxPaid)
 	if err != nil {
-		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		var mismatch *service.MismatchError
+		if errors.As(err, &mismatch) {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
+				"error":      err.Error(),
+				"mismatches": mismatch.Mismatches,
+			})
+			return
+		}
+		writeLookupError(w, r, err)
 		return
 	}
 
@@ -109,7 +205,8 @@ func (h *TaxHandler) Bulk(w http.ResponseWriter, r *http.Request) {
 		}
 		resp, err := h.svc.LookupByZIP(r.Context(), zip)
 		if err != nil {
-			results[zip] = map[string]string{"error": err.Error()}
+			status, msg := lookupError(r, err)
+			results[zip] = map[string]any{"error": msg, "status": status}
 			continue
 		}
 		results[zip] = resp
@@ -118,6 +215,30 @@ func (h *TaxHandler) Bulk(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]any{"results": results})
 }
 
+// lookupError maps a service error to an HTTP status and the message to
+// return. Only inputs with no jurisdictions are 404s; geocoder failures are
+// 502, or 503 while every breaker is open, and anything else is a 500 whose
+// details are logged rather than returned.
+func lookupError(r *http.Request, err error) (int, string) {
+	switch {
+	case errors.Is(err, service.ErrNoJurisdictions):
+		return http.StatusNotFound, err.Error()
+	case errors.Is(err, service.ErrGeocoder) && geocoder.Unavailable(err):
+		return http.StatusServiceUnavailable, "geocoder unavailable"
+	case errors.Is(err, service.ErrGeocoder):
+		slog.Warn("geocoder failed", "path", r.URL.Path, "error", err)
+		return http.StatusBadGateway, "geocoder failed"
+	default:
+		slog.Error("lookup failed", "path", r.URL.Path, "error", err)
+		return http.StatusInternalServerError, "internal error"
+	}
+}
+
+func writeLookupError(w http.ResponseWriter, r *http.Request, err error) {
+	status, msg := lookupError(r, err)
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
 func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -22,6 +22,9 @@ func TestLookupByZIP_InvalidZIP(t *testing.T) {
 		{"123456", http.StatusBadRequest},   // too long
 		{"abcde", http.StatusBadRequest},    // letters
 		{"1234%20", http.StatusBadRequest},  // space (URL-encoded)
+		{"90210-123", http.StatusBadRequest},   // short +4
+		{"90210-12345", http.StatusBadRequest}, // long +4
+		{"90210_1234", http.StatusBadRequest},  // wrong separator
 	}
 
 	for _, tt := range tests {
@@ -53,6 +56,7 @@ func TestCalculate_InvalidBody(t *testing.T) {
 		{"bad zip", `{"zip_code":"abc","amount":10}`, http.StatusBadRequest},
 		{"zero amount", `{"zip_code":"90210","amount":0}`, http.StatusBadRequest},
 		{"negative amount", `{"zip_code":"90210","amount":-5}`, http.StatusBadRequest},
+		{"unknown transaction type", `{"zip_code":"90210","amount":10,"transaction_type":"luxury"}`, http.StatusBadRequest},
 	}
 
 	for _, tt := range tests {
@@ -69,6 +73,34 @@ func TestCalculate_InvalidBody(t *testing.T) {
 	}
 }
 
+func TestUseTax_InvalidBody(t *testing.T) {
+	h := &TaxHandler{svc: nil}
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"bad json", "{bad}", http.StatusBadRequest},
+		{"missing zip", `{"amount":10}`, http.StatusBadRequest},
+		{"zero amount", `{"zip_code":"90210","amount":0}`, http.StatusBadRequest},
+		{"negative tax paid", `{"zip_code":"90210","amount":10,"tax_paid":-1}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/v1/tax/use-tax", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			h.UseTax(rr, req)
+
+			if rr.Code != tt.code {
+				t.Errorf("%s: expected %d, got %d, body: %s", tt.name, tt.code, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
 func TestBulk_InvalidBody(t *testing.T) {
 	h := &TaxHandler{svc: nil}
 
@@ -107,3 +139,44 @@ func TestLookupByAddress_MissingZIP(t *testing.T) {
 		t.Fatalf("expected 400 for missing zip, got %d", rr.Code)
 	}
 }
+
+func TestLookupByAddress_InvalidZIP(t *testing.T) {
+	h := &TaxHandler{svc: nil}
+
+	req := httptest.NewRequest("GET", "/v1/tax/address?street=123+Main&city=LA&state=CA&zip=9021", nil)
+	rr := httptest.NewRecorder()
+	h.LookupByAddress(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid zip, got %d", rr.Code)
+	}
+}
+
+func TestLookupByPoint_InvalidCoordinates(t *testing.T) {
+	h := &TaxHandler{svc: nil}
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"missing both", ""},
+		{"missing lng", "lat=34.07"},
+		{"non-numeric lat", "lat=abc&lng=-118.4"},
+		{"lat out of range", "lat=91&lng=-118.4"},
+		{"lng out of range", "lat=34.07&lng=-181"},
+		{"NaN lat", "lat=NaN&lng=-118.4"},
+		{"NaN lng", "lat=34.07&lng=nan"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/v1/tax/point?"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			h.LookupByPoint(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected 400, got %d", tt.name, rr.Code)
+			}
+		})
+	}
+}
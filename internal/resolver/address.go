@@ -3,49 +3,124 @@ package resolver
 import (
 	"context"
 	"log/slog"
+	"sort"
 
 	"github.com/prashkn/sales-tax-api/internal/geocoder"
 	"github.com/prashkn/sales-tax-api/internal/store"
 )
 
+// Resolution methods.
+const (
+	MethodGeocoded    = "geocoded"
+	MethodZIPFallback = "zip_fallback"
+)
+
+// Reasons an address lookup fell back to its ZIP.
+const (
+	FallbackNoStreet      = "no_street"
+	FallbackGeocoderError = "geocoder_error"
+	// FallbackGeocoderDown means every geocoder's circuit breaker was open,
+	// so none was called at all.
+	FallbackGeocoderDown    = "geocoder_unavailable"
+	FallbackNoMatch         = "no_match"
+	FallbackNoJurisdictions = "no_jurisdictions"
+)
+
+// Resolution is the outcome of resolving an address: the jurisdictions, how
+// they were found, and the geocoder match when there was one.
+type Resolution struct {
+	Jurisdictions []store.Jurisdiction
+	// Alternatives holds the other candidate sets when the ZIP fallback hit
+	// an ambiguous ZIP.
+	Alternatives   [][]store.Jurisdiction
+	Method         string
+	FallbackReason string
+	// Geocode is the geocoder's match. It may be set on a ZIP fallback when
+	// the match's FIPS codes had no jurisdictions on file.
+	Geocode *geocoder.Result
+	// Mismatches lists supplied fields that disagree with the ZIP or the
+	// resolved jurisdictions.
+	Mismatches []Mismatch
+}
+
 type AddressResolver struct {
-	store    *store.Store
-	geocoder *geocoder.Client
+	store    store.Store
+	zips     *ZIPResolver
+	geocoder geocoder.Geocoder
 }
 
-func NewAddressResolver(s *store.Store, gc *geocoder.Client) *AddressResolver {
-	return &AddressResolver{store: s, geocoder: gc}
+func NewAddressResolver(s store.Store, gc geocoder.Geocoder) *AddressResolver {
+	return &AddressResolver{store: s, zips: NewZIPResolver(s), geocoder: gc}
 }
 
 // Resolve geocodes an address to a precise set of jurisdictions.
-// If a full street address is provided, it calls the Census Geocoder API to
+// If a full street address is provided, it calls the configured geocoder to
 // get exact FIPS codes. If geocoding fails or only a ZIP is provided, it
-// falls back to the ZIP-based lookup.
-func (r *AddressResolver) Resolve(ctx context.Context, street, city, state, zip string) ([]store.Jurisdiction, error) {
+// falls back to the ZIP-based lookup, which honors a ZIP+4 extension. The
+// returned Resolution records which path was taken and why, and any
+// disagreement between the supplied state or city and the result.
+func (r *AddressResolver) Resolve(ctx context.Context, street, city, state, zip string) (*Resolution, error) {
+	res, err := r.resolve(ctx, street, city, state, zip)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Jurisdictions) > 0 {
+		res.Mismatches = validateAddress(state, city, zip, res.Jurisdictions)
+	}
+	return res, nil
+}
+
+func (r *AddressResolver) resolve(ctx context.Context, street, city, state, zip string) (*Resolution, error) {
+	res := &Resolution{Method: MethodZIPFallback, FallbackReason: FallbackNoStreet}
+
 	// If we have a street address, attempt geocoding for precise resolution.
 	if street != "" {
-		result, err := r.geocoder.Geocode(ctx, street, city, state, zip)
-		if err != nil {
+		zip5, _ := SplitZIP(zip)
+		result, err := r.geocoder.Geocode(ctx, street, city, state, zip5)
+		switch {
+		case geocoder.Unavailable(err):
+			res.FallbackReason = FallbackGeocoderDown
+		case err != nil:
 			slog.Warn("geocoding failed, falling back to zip", "error", err, "zip", zip)
-		} else if result != nil {
-			jurisdictions, err := r.resolveFromGeocode(ctx, result)
+			res.FallbackReason = FallbackGeocoderError
+		case result == nil:
+			// Geocoder returned no match — fall through to ZIP.
+			res.FallbackReason = FallbackNoMatch
+		default:
+			res.Geocode = result
+			res.FallbackReason = FallbackNoJurisdictions
+			jurisdictions, err := resolveFromGeocode(ctx, r.store, result, zip)
 			if err != nil {
 				slog.Warn("fips lookup failed after geocode, falling back to zip", "error", err, "zip", zip)
 			} else if len(jurisdictions) > 0 {
-				return jurisdictions, nil
+				res.Jurisdictions = jurisdictions
+				res.Method = MethodGeocoded
+				res.FallbackReason = ""
+				return res, nil
 			}
 		}
-		// Geocoder returned no match — fall through to ZIP.
 	}
 
 	// Fall back to ZIP-based resolution.
-	return r.store.GetJurisdictionsByZIP(ctx, zip)
+	candidates, err := r.zips.Candidates(ctx, zip)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) > 0 {
+		res.Jurisdictions = candidates[0]
+		res.Alternatives = candidates[1:]
+	}
+	return res, nil
 }
 
 // resolveFromGeocode builds a list of FIPS codes from the geocoder result
-// and queries the jurisdictions table. The query also picks up any
-// special_district children of the matched county.
-func (r *AddressResolver) resolveFromGeocode(ctx context.Context, result *geocoder.Result) ([]store.Jurisdiction, error) {
+// and queries the jurisdictions table. Special districts are matched by
+// location: those whose polygons contain the point, plus those whose ZIP+4
+// ranges cover zip when it has an extension. Districts the request can't
+// locate are attached by parent as a fallback and marked ParentFallback:
+// those with no boundary on file, ZIP+4 districts when zip has no ranged
+// extension, and polygon districts when no district polygons are loaded.
+func resolveFromGeocode(ctx context.Context, s store.Store, result *geocoder.Result, zip string) ([]store.Jurisdiction, error) {
 	var fipsCodes []string
 
 	if result.StateFIPS != "" {
@@ -57,10 +132,70 @@ func (r *AddressResolver) resolveFromGeocode(ctx context.Context, result *geocod
 	if result.PlaceFIPS != "" {
 		fipsCodes = append(fipsCodes, result.PlaceFIPS)
 	}
+	if result.SubdivisionFIPS != "" {
+		fipsCodes = append(fipsCodes, result.SubdivisionFIPS)
+	}
 
 	if len(fipsCodes) == 0 {
 		return nil, nil
 	}
+	parents := fipsCodes[1:]
+
+	// Tribal areas don't parent districts, so they join after the parents.
+	if result.TribalFIPS != "" {
+		fipsCodes = append(fipsCodes, result.TribalFIPS)
+	}
 
-	return r.store.GetJurisdictionsByFIPSCodes(ctx, fipsCodes)
+	fipsCodes = append(fipsCodes, result.SpecialDistricts...)
+	unlocated := []string{store.BoundaryParent}
+	if !result.HasLayer(geocoder.LayerSpecialDistrict) {
+		unlocated = append(unlocated, store.BoundaryPolygon)
+	}
+	var ranged []store.Jurisdiction
+	if zip5, plus4 := SplitZIP(zip); plus4 != "" {
+		var err error
+		ranged, err = s.GetJurisdictionsByZIP4(ctx, zip5, plus4)
+		if err != nil {
+			return nil, err
+		}
+		for _, j := range ranged {
+			if j.Type == "special_district" {
+				fipsCodes = append(fipsCodes, j.FIPSCode)
+			}
+		}
+	}
+	if len(ranged) == 0 {
+		unlocated = append(unlocated, store.BoundaryZIP4)
+	}
+
+	jurisdictions, err := s.GetJurisdictionsByFIPSCodes(ctx, fipsCodes)
+	if err != nil {
+		return nil, err
+	}
+	if len(parents) == 0 {
+		return jurisdictions, nil
+	}
+
+	fallback, err := s.GetFallbackDistricts(ctx, parents, unlocated)
+	if err != nil {
+		return nil, err
+	}
+	return mergeDistricts(jurisdictions, fallback), nil
+}
+
+// mergeDistricts appends the fallback districts that weren't already located,
+// keeping the result ordered by type like the store's queries.
+func mergeDistricts(located, fallback []store.Jurisdiction) []store.Jurisdiction {
+	seen := make(map[string]bool, len(located))
+	for _, j := range located {
+		seen[j.FIPSCode] = true
+	}
+	merged := located
+	for _, j := range fallback {
+		if !seen[j.FIPSCode] {
+			merged = append(merged, j)
+		}
+	}
+	sort.SliceStable(merged, func(a, b int) bool { return merged[a].Type < merged[b].Type })
+	return merged
 }
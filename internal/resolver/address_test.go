@@ -0,0 +1,95 @@
+package resolver
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/prashkn/sales-tax-api/internal/geocoder"
+	"github.com/prashkn/sales-tax-api/internal/store"
+	"github.com/prashkn/sales-tax-api/internal/store/storetest"
+)
+
+func TestMergeDistricts(t *testing.T) {
+	located := []store.Jurisdiction{
+		{FIPSCode: "06", Type: "state"},
+		{FIPSCode: "06037", Type: "county"},
+		{FIPSCode: "06037SD02", Type: "special_district"},
+	}
+	fallback := []store.Jurisdiction{
+		{FIPSCode: "06037SD01", Type: "special_district", ParentFallback: true},
+		{FIPSCode: "06037SD02", Type: "special_district", ParentFallback: true},
+	}
+
+	got := mergeDistricts(located, fallback)
+	var codes []string
+	for _, j := range got {
+		codes = append(codes, j.FIPSCode)
+	}
+	want := "06037,06037SD02,06037SD01,06"
+	if strings.Join(codes, ",") != want {
+		t.Fatalf("got %v, want %s", codes, want)
+	}
+	if got[1].ParentFallback {
+		t.Error("located district should not be marked as a fallback")
+	}
+	if !got[2].ParentFallback {
+		t.Error("fallback district should be marked")
+	}
+}
+
+func TestResolveFromGeocode_AttachesUnlocatedDistricts(t *testing.T) {
+	snap := storetest.Sample()
+	county := "06037"
+	for _, d := range []struct{ fips, boundary string }{
+		{"06037SD02", store.BoundaryZIP4},
+		{"06037SD03", store.BoundaryPolygon},
+	} {
+		snap.Jurisdictions = append(snap.Jurisdictions, store.SnapshotJurisdiction{
+			Jurisdiction: store.Jurisdiction{FIPSCode: d.fips, Type: "special_district", StateFIPS: "06", ParentFIPS: &county},
+			BoundaryType: d.boundary,
+		})
+	}
+	// 90001-0001..0499 is inside the ZIP+4 district; 0500..0999 isn't.
+	snap.ZIP4s = append(snap.ZIP4s,
+		store.ZIP4Jurisdiction{ZIPCode: "90001", Plus4Low: "0001", Plus4High: "0499", FIPSCode: "06037SD02"},
+		store.ZIP4Jurisdiction{ZIPCode: "90001", Plus4Low: "0500", Plus4High: "0999", FIPSCode: "0644000"},
+	)
+	s := storetest.New(t, snap)
+
+	census := &geocoder.Result{StateFIPS: "06", CountyFIPS: "06037", PlaceFIPS: "0644000"}
+	local := &geocoder.Result{StateFIPS: "06", CountyFIPS: "06037", PlaceFIPS: "0644000",
+		Layers: []string{geocoder.LayerCounty, geocoder.LayerPlace, geocoder.LayerSpecialDistrict}}
+
+	tests := []struct {
+		name   string
+		result *geocoder.Result
+		zip    string
+		want   []string
+	}{
+		{"5-digit ZIP attaches every unlocated district", census, "90001", []string{"06037SD01", "06037SD02", "06037SD03"}},
+		{"point lookup has no ZIP", census, "", []string{"06037SD01", "06037SD02", "06037SD03"}},
+		{"+4 inside the range locates it", census, "900010100", []string{"06037SD01", "06037SD02", "06037SD03"}},
+		{"+4 outside the range rules it out", census, "900010600", []string{"06037SD01", "06037SD03"}},
+		{"district polygons loaded", local, "90001", []string{"06037SD01", "06037SD02"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jurisdictions, err := resolveFromGeocode(context.Background(), s, tt.result, tt.zip)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var districts []string
+			for _, j := range jurisdictions {
+				if j.Type == "special_district" {
+					districts = append(districts, j.FIPSCode)
+				}
+			}
+			slices.Sort(districts)
+			if !slices.Equal(districts, tt.want) {
+				t.Errorf("districts = %v, want %v", districts, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/prashkn/sales-tax-api/internal/geocoder"
+	"github.com/prashkn/sales-tax-api/internal/store"
+)
+
+// ErrGeocoder is matched by errors the geocoder returned, as opposed to
+// store errors, so callers can report them as an upstream failure.
+var ErrGeocoder = errors.New("geocoder failed")
+
+// geocoderError wraps a reverser error. It matches ErrGeocoder without
+// joining it, so geocoder.Unavailable still sees the reverser's error alone.
+type geocoderError struct{ err error }
+
+func (e *geocoderError) Error() string        { return "reverse geocoding: " + e.err.Error() }
+func (e *geocoderError) Unwrap() error        { return e.err }
+func (e *geocoderError) Is(target error) bool { return target == ErrGeocoder }
+
+type PointResolver struct {
+	store    store.Store
+	reverser geocoder.Reverser
+}
+
+// NewPointResolver returns a resolver backed by rv: the Census client
+// resolves points remotely, a boundary index does it from local polygons.
+func NewPointResolver(s store.Store, rv geocoder.Reverser) *PointResolver {
+	return &PointResolver{store: s, reverser: rv}
+}
+
+// Resolve maps a latitude/longitude to its jurisdictions. Unlike address
+// resolution there is no ZIP to fall back on, so geocoder errors are
+// returned to the caller.
+func (r *PointResolver) Resolve(ctx context.Context, lat, lng float64) ([]store.Jurisdiction, error) {
+	result, err := r.reverser.Reverse(ctx, lat, lng)
+	if err != nil {
+		return nil, &geocoderError{err: err}
+	}
+	if result == nil {
+		return nil, nil
+	}
+	return resolveFromGeocode(ctx, r.store, result, "")
+}
@@ -7,13 +7,24 @@ import (
 )
 
 type RateResolver struct {
-	store *store.Store
+	store store.Store
 }
 
-func NewRateResolver(s *store.Store) *RateResolver {
+func NewRateResolver(s store.Store) *RateResolver {
 	return &RateResolver{store: s}
 }
 
 func (r *RateResolver) GetRate(ctx context.Context, fipsCode string) (*store.Rate, error) {
 	return r.store.GetRateByFIPS(ctx, fipsCode)
-}
\ No newline at end of file
+}
+
+// GetRates returns every active rate type for a jurisdiction.
+func (r *RateResolver) GetRates(ctx context.Context, fipsCode string) ([]store.Rate, error) {
+	return r.store.GetRatesByFIPS(ctx, fipsCode)
+}
+
+// GetRatesForFIPSCodes returns every active rate type for a batch of
+// jurisdictions, keyed by FIPS code.
+func (r *RateResolver) GetRatesForFIPSCodes(ctx context.Context, fipsCodes []string) (map[string][]store.Rate, error) {
+	return r.store.GetActiveRatesByFIPSCodes(ctx, fipsCodes)
+}
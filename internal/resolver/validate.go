@@ -0,0 +1,115 @@
+package resolver
+
+import (
+	"strings"
+
+	"github.com/prashkn/sales-tax-api/internal/address"
+	"github.com/prashkn/sales-tax-api/internal/store"
+)
+
+// Mismatch severities.
+const (
+	// SeverityError means the resolved rates belong to a different state
+	// than the one supplied, so they shouldn't be returned.
+	SeverityError = "error"
+	// SeverityWarning means the input looks inconsistent but the rates may
+	// still be right, e.g. a mailing city that isn't the taxing city.
+	SeverityWarning = "warning"
+)
+
+// Mismatch sources.
+const (
+	SourceZIPReference  = "zip_reference"
+	SourceJurisdictions = "jurisdictions"
+)
+
+// Mismatch is a disagreement between a supplied address field and what the
+// ZIP or resolved jurisdictions imply.
+type Mismatch struct {
+	Field    string // "state" or "city"
+	Supplied string
+	Expected string
+	Source   string
+	Severity string
+}
+
+// validateAddress cross-checks the supplied state and city against the ZIP
+// reference table and the resolved jurisdictions. state and city are
+// expected in address.Normalize form.
+func validateAddress(state, city, zip string, jurisdictions []store.Jurisdiction) []Mismatch {
+	var mismatches []Mismatch
+
+	if address.IsStateCode(state) {
+		// ZIPs near state lines are sometimes delivered across them, so the
+		// reference table alone only warns.
+		if ref, ok := address.StateForZIP(zip); ok && ref != state {
+			mismatches = append(mismatches, Mismatch{
+				Field: "state", Supplied: state, Expected: ref,
+				Source: SourceZIPReference, Severity: SeverityWarning,
+			})
+		}
+		if resolved, ok := resolvedState(jurisdictions); ok && resolved != state {
+			mismatches = append(mismatches, Mismatch{
+				Field: "state", Supplied: state, Expected: resolved,
+				Source: SourceJurisdictions, Severity: SeverityError,
+			})
+		}
+	}
+
+	if city != "" {
+		if resolved, ok := resolvedCity(city, jurisdictions); !ok {
+			mismatches = append(mismatches, Mismatch{
+				Field: "city", Supplied: city, Expected: resolved,
+				Source: SourceJurisdictions, Severity: SeverityWarning,
+			})
+		}
+	}
+
+	return mismatches
+}
+
+// resolvedState returns the USPS code of the state the jurisdictions are in.
+func resolvedState(jurisdictions []store.Jurisdiction) (string, bool) {
+	for _, j := range jurisdictions {
+		if code, ok := address.StateForFIPS(j.StateFIPS); ok {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// resolvedCity reports whether city matches one of the resolved municipal
+// jurisdictions (cities, towns, townships), returning the first one's name
+// for the mismatch details. Addresses with no municipal jurisdiction always
+// match, since their mailing city isn't a taxing body.
+func resolvedCity(city string, jurisdictions []store.Jurisdiction) (string, bool) {
+	var first string
+	for _, j := range jurisdictions {
+		switch j.Type {
+		case "city", "county_subdivision", "township":
+		default:
+			continue
+		}
+		if first == "" {
+			first = j.Name
+		}
+		if cityKey(j.Name) == cityKey(city) {
+			return j.Name, true
+		}
+	}
+	return first, first == ""
+}
+
+// cityKey reduces a city name to a comparable form, dropping the "City of"
+// style prefixes and " City"/" town" suffixes that jurisdiction names (and
+// Census subdivision names) often carry.
+func cityKey(name string) string {
+	s := address.NormalizeCity(name)
+	for _, prefix := range []string{"CITY OF ", "TOWN OF ", "VILLAGE OF ", "TOWNSHIP OF "} {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	for _, suffix := range []string{" CITY", " TOWN", " TOWNSHIP"} {
+		s = strings.TrimSuffix(s, suffix)
+	}
+	return s
+}
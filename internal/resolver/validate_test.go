@@ -0,0 +1,68 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/prashkn/sales-tax-api/internal/store"
+)
+
+var newYork = []store.Jurisdiction{
+	{FIPSCode: "36", Name: "New York", Type: "state", StateFIPS: "36"},
+	{FIPSCode: "36061", Name: "New York County", Type: "county", StateFIPS: "36"},
+	{FIPSCode: "3651000", Name: "New York City", Type: "city", StateFIPS: "36"},
+}
+
+func TestValidateAddress_StateMismatch(t *testing.T) {
+	got := validateAddress("CA", "", "10001", newYork)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 mismatches, got %+v", got)
+	}
+
+	if got[0].Source != SourceZIPReference || got[0].Expected != "NY" || got[0].Severity != SeverityWarning {
+		t.Errorf("unexpected zip reference mismatch: %+v", got[0])
+	}
+	if got[1].Source != SourceJurisdictions || got[1].Expected != "NY" || got[1].Severity != SeverityError {
+		t.Errorf("unexpected jurisdictions mismatch: %+v", got[1])
+	}
+}
+
+func TestValidateAddress_Consistent(t *testing.T) {
+	for _, city := range []string{"", "NEW YORK", "NEW YORK CITY"} {
+		if got := validateAddress("NY", city, "10001", newYork); len(got) != 0 {
+			t.Errorf("city %q: expected no mismatches, got %+v", city, got)
+		}
+	}
+}
+
+func TestValidateAddress_CityMismatchWarns(t *testing.T) {
+	got := validateAddress("NY", "BROOKLYN", "10001", newYork)
+	if len(got) != 1 || got[0].Field != "city" || got[0].Severity != SeverityWarning {
+		t.Fatalf("expected a city warning, got %+v", got)
+	}
+	if got[0].Expected != "New York City" {
+		t.Errorf("Expected = %q, want %q", got[0].Expected, "New York City")
+	}
+}
+
+func TestValidateAddress_UnincorporatedSkipsCity(t *testing.T) {
+	got := validateAddress("NY", "ANYWHERE", "10001", newYork[:2])
+	if len(got) != 0 {
+		t.Errorf("expected no mismatches, got %+v", got)
+	}
+}
+
+func TestValidateAddress_UnknownStateSkipped(t *testing.T) {
+	if got := validateAddress("NARNIA", "", "10001", newYork); len(got) != 0 {
+		t.Errorf("expected no mismatches, got %+v", got)
+	}
+}
+
+func TestValidateAddress_TownMatchesCity(t *testing.T) {
+	lexington := []store.Jurisdiction{
+		{FIPSCode: "25", Name: "Massachusetts", Type: "state", StateFIPS: "25"},
+		{FIPSCode: "2501735215", Name: "Lexington town", Type: "county_subdivision", StateFIPS: "25"},
+	}
+	if got := validateAddress("MA", "LEXINGTON", "02420", lexington); len(got) != 0 {
+		t.Errorf("expected no mismatches, got %+v", got)
+	}
+}
@@ -2,18 +2,196 @@ package resolver
 
 import (
 	"context"
+	"sort"
+	"strings"
 
 	"github.com/prashkn/sales-tax-api/internal/store"
 )
 
 type ZIPResolver struct {
-	store *store.Store
+	store store.Store
 }
 
-func NewZIPResolver(s *store.Store) *ZIPResolver {
+func NewZIPResolver(s store.Store) *ZIPResolver {
 	return &ZIPResolver{store: s}
 }
 
+// Resolve returns the primary jurisdiction set for a 5-digit ZIP or ZIP+4.
 func (r *ZIPResolver) Resolve(ctx context.Context, zipCode string) ([]store.Jurisdiction, error) {
-	return r.store.GetJurisdictionsByZIP(ctx, zipCode)
-}
\ No newline at end of file
+	candidates, err := r.Candidates(ctx, zipCode)
+	if err != nil || len(candidates) == 0 {
+		return nil, err
+	}
+	return candidates[0], nil
+}
+
+// Candidates returns the distinct jurisdiction sets a ZIP can resolve to,
+// primary first. When a +4 extension falls inside a known range, that
+// range's set is the only candidate; otherwise the 5-digit mappings are
+// grouped so a ZIP spanning two cities yields one set per city instead of
+// both city rates stacked together.
+func (r *ZIPResolver) Candidates(ctx context.Context, zipCode string) ([][]store.Jurisdiction, error) {
+	zip, plus4 := SplitZIP(zipCode)
+	if plus4 != "" {
+		jurisdictions, err := r.store.GetJurisdictionsByZIP4(ctx, zip, plus4)
+		if err != nil {
+			return nil, err
+		}
+		if len(jurisdictions) > 0 {
+			return [][]store.Jurisdiction{jurisdictions}, nil
+		}
+	}
+
+	matches, err := r.store.GetZIPMatches(ctx, zip)
+	if err != nil {
+		return nil, err
+	}
+	return groupCandidates(matches), nil
+}
+
+// CandidatesForZIPs is Candidates for a batch of 5-digit ZIPs, resolved with
+// one query. ZIPs with no mappings are absent from the result.
+func (r *ZIPResolver) CandidatesForZIPs(ctx context.Context, zips []string) (map[string][][]store.Jurisdiction, error) {
+	matches, err := r.store.GetZIPMatchesForZIPs(ctx, zips)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make(map[string][][]store.Jurisdiction, len(matches))
+	for zip, m := range matches {
+		candidates[zip] = groupCandidates(m)
+	}
+	return candidates, nil
+}
+
+// groupCandidates splits a ZIP's mappings into candidate jurisdiction sets.
+//
+// States, counties (and county-equivalent boroughs), cities and county
+// subdivisions (including townships) are alternatives to one another: a ZIP
+// with two cities or two towns produces two sets. Special districts and
+// tribal areas stack rather than compete, so each set gets the districts
+// whose parent is in that set, plus any whose parent isn't mapped to the ZIP
+// at all. Primary mappings sort first, so the first set is the primary
+// answer.
+func groupCandidates(matches []store.ZIPMatch) [][]store.Jurisdiction {
+	if len(matches) == 0 {
+		return nil
+	}
+
+	var states, counties, cities, subdivisions, specials []store.ZIPMatch
+	for _, m := range matches {
+		switch m.Type {
+		case "state":
+			states = append(states, m)
+		case "county", "borough":
+			counties = append(counties, m)
+		case "city":
+			cities = append(cities, m)
+		case "county_subdivision", "township":
+			subdivisions = append(subdivisions, m)
+		default:
+			specials = append(specials, m)
+		}
+	}
+
+	// Unambiguous: keep the mappings as a single set in query order.
+	if len(states) <= 1 && len(counties) <= 1 && len(cities) <= 1 && len(subdivisions) <= 1 {
+		set := make([]store.Jurisdiction, len(matches))
+		for i, m := range matches {
+			set[i] = m.Jurisdiction
+		}
+		return [][]store.Jurisdiction{set}
+	}
+
+	primaryFirst(counties)
+	primaryFirst(cities)
+	primaryFirst(subdivisions)
+
+	mapped := make(map[string]bool, len(counties)+len(cities)+len(subdivisions))
+	for _, group := range [][]store.ZIPMatch{counties, cities, subdivisions} {
+		for _, m := range group {
+			mapped[m.FIPSCode] = true
+		}
+	}
+
+	// A ZIP without a county mapping still needs one pass over its cities.
+	if len(counties) == 0 {
+		counties = []store.ZIPMatch{{}}
+	}
+
+	var candidates [][]store.Jurisdiction
+	for _, county := range counties {
+		for _, city := range inCounty(cities, county, mapped) {
+			for _, subdivision := range inCounty(subdivisions, county, mapped) {
+				var set []store.Jurisdiction
+				for _, st := range states {
+					if county.FIPSCode == "" || len(states) == 1 || st.FIPSCode == county.StateFIPS {
+						set = append(set, st.Jurisdiction)
+					}
+				}
+				for _, m := range []store.ZIPMatch{county, city, subdivision} {
+					if m.FIPSCode != "" {
+						set = append(set, m.Jurisdiction)
+					}
+				}
+				for _, sd := range specials {
+					parent := parentOf(sd)
+					if !mapped[parent] || (parent != "" && (parent == county.FIPSCode || parent == city.FIPSCode || parent == subdivision.FIPSCode)) {
+						set = append(set, sd.Jurisdiction)
+					}
+				}
+				candidates = append(candidates, set)
+			}
+		}
+	}
+	return candidates
+}
+
+// inCounty returns the options inside county, or whose county isn't mapped
+// to the ZIP. With none, it returns a single empty match standing for the
+// part of the county outside them all, e.g. unincorporated land.
+func inCounty(options []store.ZIPMatch, county store.ZIPMatch, mapped map[string]bool) []store.ZIPMatch {
+	var in []store.ZIPMatch
+	for _, m := range options {
+		parent := parentOf(m)
+		if county.FIPSCode == "" || parent == county.FIPSCode || !mapped[parent] {
+			in = append(in, m)
+		}
+	}
+	if len(in) == 0 {
+		return []store.ZIPMatch{{}}
+	}
+	return in
+}
+
+func primaryFirst(matches []store.ZIPMatch) {
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].IsPrimary && !matches[j].IsPrimary
+	})
+}
+
+func parentOf(m store.ZIPMatch) string {
+	if m.ParentFIPS == nil {
+		return ""
+	}
+	return *m.ParentFIPS
+}
+
+// SplitZIP splits "90210-1234" or "902101234" into ("90210", "1234").
+// A plain 5-digit ZIP is returned with an empty extension.
+func SplitZIP(zipCode string) (zip, plus4 string) {
+	digits := strings.ReplaceAll(zipCode, "-", "")
+	if len(digits) == 9 {
+		return digits[:5], digits[5:]
+	}
+	return zipCode, ""
+}
+
+// FormatZIP returns the canonical "12345" or "12345-6789" form of a ZIP so
+// equivalent inputs share cache entries and response values.
+func FormatZIP(zipCode string) string {
+	zip, plus4 := SplitZIP(zipCode)
+	if plus4 == "" {
+		return zip
+	}
+	return zip + "-" + plus4
+}
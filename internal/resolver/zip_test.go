@@ -0,0 +1,163 @@
+package resolver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prashkn/sales-tax-api/internal/store"
+)
+
+func TestSplitZIP(t *testing.T) {
+	tests := []struct {
+		in        string
+		zip, plus string
+	}{
+		{"90210", "90210", ""},
+		{"90210-1234", "90210", "1234"},
+		{"902101234", "90210", "1234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			zip, plus4 := SplitZIP(tt.in)
+			if zip != tt.zip || plus4 != tt.plus {
+				t.Errorf("SplitZIP(%q) = (%q, %q), want (%q, %q)", tt.in, zip, plus4, tt.zip, tt.plus)
+			}
+		})
+	}
+}
+
+func TestFormatZIP(t *testing.T) {
+	tests := map[string]string{
+		"90210":      "90210",
+		"90210-1234": "90210-1234",
+		"902101234":  "90210-1234",
+	}
+
+	for in, want := range tests {
+		if got := FormatZIP(in); got != want {
+			t.Errorf("FormatZIP(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func match(fips, typ, parent string, primary bool) store.ZIPMatch {
+	m := store.ZIPMatch{IsPrimary: primary}
+	m.FIPSCode = fips
+	m.Type = typ
+	m.StateFIPS = fips[:2]
+	if parent != "" {
+		m.ParentFIPS = &parent
+	}
+	return m
+}
+
+func fipsCodes(set []store.Jurisdiction) []string {
+	codes := make([]string, len(set))
+	for i, j := range set {
+		codes[i] = j.FIPSCode
+	}
+	return codes
+}
+
+func TestGroupCandidates_SingleSet(t *testing.T) {
+	matches := []store.ZIPMatch{
+		match("06", "state", "", true),
+		match("06037", "county", "06", true),
+		match("0603744000", "city", "06037", true),
+		match("06037SD01", "special_district", "06037", true),
+	}
+
+	candidates := groupCandidates(matches)
+	if len(candidates) != 1 {
+		t.Fatalf("expected 1 candidate, got %d", len(candidates))
+	}
+	if len(candidates[0]) != 4 {
+		t.Errorf("expected all 4 jurisdictions in the set, got %v", fipsCodes(candidates[0]))
+	}
+}
+
+func TestGroupCandidates_TwoCities(t *testing.T) {
+	matches := []store.ZIPMatch{
+		match("06", "state", "", true),
+		match("06037", "county", "06", true),
+		match("0644000", "city", "06037", false),
+		match("0603744000", "city", "06037", true),
+		match("06037SD01", "special_district", "06037", true),
+	}
+
+	candidates := groupCandidates(matches)
+	if len(candidates) != 2 {
+		t.Fatalf("expected 2 candidates, got %d", len(candidates))
+	}
+
+	want := [][]string{
+		{"06", "06037", "0603744000", "06037SD01"},
+		{"06", "06037", "0644000", "06037SD01"},
+	}
+	for i, set := range candidates {
+		got := strings.Join(fipsCodes(set), ",")
+		if got != strings.Join(want[i], ",") {
+			t.Errorf("candidate %d = %s, want %s", i, got, strings.Join(want[i], ","))
+		}
+	}
+}
+
+func TestGroupCandidates_TwoCounties(t *testing.T) {
+	// A ZIP straddling two counties; the special district belongs to only one.
+	matches := []store.ZIPMatch{
+		match("17", "state", "", true),
+		match("17031", "county", "17", true),
+		match("17043", "county", "17", false),
+		match("1714000", "city", "17031", true),
+		match("17031SD01", "special_district", "17031", true),
+	}
+
+	candidates := groupCandidates(matches)
+	if len(candidates) != 2 {
+		t.Fatalf("expected 2 candidates, got %d", len(candidates))
+	}
+
+	want := [][]string{
+		{"17", "17031", "1714000", "17031SD01"},
+		{"17", "17043"},
+	}
+	for i, set := range candidates {
+		got := strings.Join(fipsCodes(set), ",")
+		if got != strings.Join(want[i], ",") {
+			t.Errorf("candidate %d = %s, want %s", i, got, strings.Join(want[i], ","))
+		}
+	}
+}
+
+func TestGroupCandidates_TwoTowns(t *testing.T) {
+	// A ZIP spanning two New England towns in one county.
+	matches := []store.ZIPMatch{
+		match("25", "state", "", true),
+		match("25017", "county", "25", true),
+		match("2501772600", "county_subdivision", "25017", false),
+		match("2501756130", "county_subdivision", "25017", true),
+	}
+
+	candidates := groupCandidates(matches)
+	if len(candidates) != 2 {
+		t.Fatalf("expected 2 candidates, got %d", len(candidates))
+	}
+
+	want := [][]string{
+		{"25", "25017", "2501756130"},
+		{"25", "25017", "2501772600"},
+	}
+	for i, set := range candidates {
+		got := strings.Join(fipsCodes(set), ",")
+		if got != strings.Join(want[i], ",") {
+			t.Errorf("candidate %d = %s, want %s", i, got, strings.Join(want[i], ","))
+		}
+	}
+}
+
+func TestGroupCandidates_Empty(t *testing.T) {
+	if got := groupCandidates(nil); got != nil {
+		t.Fatalf("expected nil for no matches, got %v", got)
+	}
+}
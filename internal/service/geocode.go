@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+
+	"github.com/prashkn/sales-tax-api/internal/cache"
+	"github.com/prashkn/sales-tax-api/internal/geocoder"
+)
+
+// cachedGeocoder caches geocoder results by normalized address, including
+// no-match results, so repeat checkouts to the same address skip the
+// geocoder. Errors are never cached, and a Chain reports a miss as an error
+// when any provider failed, so only misses every provider agreed on are.
+type cachedGeocoder struct {
+	next  geocoder.Geocoder
+	cache Cache
+}
+
+// geocodeEntry wraps the result so a cached miss (nil Result) can be told
+// apart from a cache miss.
+type geocodeEntry struct {
+	Result *geocoder.Result `json:"result"`
+}
+
+func (g *cachedGeocoder) Geocode(ctx context.Context, street, city, state, zip string) (*geocoder.Result, error) {
+	key := cache.AddressKey(street, city, state, zip)
+
+	var entry geocodeEntry
+	if err := g.cache.GetGeocode(ctx, key, &entry); err == nil {
+		return entry.Result, nil
+	}
+
+	result, err := g.next.Geocode(ctx, street, city, state, zip)
+	if err != nil {
+		return nil, err
+	}
+
+	// Best-effort, like the rate cache.
+	_ = g.cache.SetGeocode(ctx, key, geocodeEntry{Result: result}, result != nil)
+	return result, nil
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prashkn/sales-tax-api/internal/cache"
+	"github.com/prashkn/sales-tax-api/internal/geocoder"
+	"github.com/prashkn/sales-tax-api/internal/geocoder/geocodertest"
+	"github.com/prashkn/sales-tax-api/internal/resolver"
+	"github.com/prashkn/sales-tax-api/internal/store"
+	"github.com/prashkn/sales-tax-api/internal/store/storetest"
+)
+
+// newTestService returns a TaxService over the sample data, with an
+// in-memory cache configured by opts.
+func newTestService(t *testing.T, opts cache.Options, gc geocoder.Geocoder) (*TaxService, *storetest.Fake) {
+	t.Helper()
+	s := storetest.New(t, storetest.Sample())
+	if gc == nil {
+		gc = &geocodertest.Geocoder{}
+	}
+	return NewTaxService(s, cache.New(cache.NewMemory(100), opts), gc, &geocodertest.Reverser{}), s
+}
+
+func TestLookupByZIP_SeededAndCached(t *testing.T) {
+	ts, s := newTestService(t, cache.Options{TTL: time.Hour}, nil)
+	ctx := context.Background()
+
+	resp, err := ts.LookupByZIP(ctx, "90210")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !approx(resp.CombinedRate, 0.0925) {
+		t.Errorf("CombinedRate = %v, want 0.0925", resp.CombinedRate)
+	}
+	if len(resp.Jurisdictions) != 4 || resp.Ambiguous {
+		t.Errorf("got %d jurisdictions, ambiguous=%v; want 4, false", len(resp.Jurisdictions), resp.Ambiguous)
+	}
+	if !approx(resp.Breakdown.Special, 0.005) {
+		t.Errorf("Breakdown.Special = %v, want 0.005", resp.Breakdown.Special)
+	}
+
+	calls := s.Calls()
+	if _, err := ts.LookupByZIP(ctx, "90210"); err != nil {
+		t.Fatal(err)
+	}
+	if s.Calls() != calls {
+		t.Errorf("second lookup made %d store calls, want it served from cache", s.Calls()-calls)
+	}
+}
+
+func TestLookupByZIP_UnknownZIPCachedAsNotFound(t *testing.T) {
+	ts, s := newTestService(t, cache.Options{TTL: time.Hour, NegativeTTL: time.Minute}, nil)
+	ctx := context.Background()
+
+	if _, err := ts.LookupByZIP(ctx, "00000"); !errors.Is(err, ErrNoJurisdictions) {
+		t.Fatalf("err = %v, want ErrNoJurisdictions", err)
+	}
+	calls := s.Calls()
+	if _, err := ts.LookupByZIP(ctx, "00000"); !errors.Is(err, ErrNoJurisdictions) {
+		t.Fatalf("cached err = %v, want ErrNoJurisdictions", err)
+	}
+	if s.Calls() != calls {
+		t.Error("repeat lookup for an unknown ZIP reached the store")
+	}
+}
+
+func TestLookupByZIP_ServesStaleWhenStoreDown(t *testing.T) {
+	// A zero TTL makes every cached entry stale at once.
+	ts, s := newTestService(t, cache.Options{StaleTTL: time.Hour}, nil)
+	ctx := context.Background()
+
+	if _, err := ts.LookupByZIP(ctx, "90001"); err != nil {
+		t.Fatal(err)
+	}
+	s.SetErr(errors.New("connection refused"))
+
+	resp, err := ts.LookupByZIP(ctx, "90001")
+	if err != nil {
+		t.Fatalf("LookupByZIP with the store down = %v, want the stale copy", err)
+	}
+	if !resp.Meta.Stale || !approx(resp.CombinedRate, 0.08) {
+		t.Errorf("got stale=%v rate=%v, want a stale 0.08", resp.Meta.Stale, resp.CombinedRate)
+	}
+
+	if _, err := ts.LookupByZIP(ctx, "90210"); err == nil {
+		t.Error("uncached ZIP succeeded with the store down")
+	}
+}
+
+func TestCalculate_Seeded(t *testing.T) {
+	ts, _ := newTestService(t, cache.Options{TTL: time.Hour}, nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		zip, rateType string
+		tax           float64
+	}{
+		{"90001", store.RateGeneral, 8.00},
+		{"90210", store.RateGeneral, 9.25},
+		// The state's lodging rate replaces its general rate.
+		{"90210", store.RateLodging, 14.00},
+	}
+	for _, tt := range tests {
+		t.Run(tt.zip+"/"+tt.rateType, func(t *testing.T) {
+			resp, err := ts.Calculate(ctx, tt.zip, 100, tt.rateType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !approx(resp.TaxAmount, tt.tax) || !approx(resp.Total, 100+tt.tax) {
+				t.Errorf("tax = %v, total = %v; want %v, %v", resp.TaxAmount, resp.Total, tt.tax, 100+tt.tax)
+			}
+		})
+	}
+}
+
+func TestLookupByAddress_GeocodedAndFallback(t *testing.T) {
+	gc := &geocodertest.Geocoder{Results: map[string]*geocoder.Result{
+		"200 N Spring St": {StateFIPS: "06", CountyFIPS: "06037", PlaceFIPS: "0644000"},
+	}}
+	ts, _ := newTestService(t, cache.Options{TTL: time.Hour, GeocodeHitTTL: time.Hour, GeocodeMissTTL: time.Hour}, gc)
+	ctx := context.Background()
+
+	resp, err := ts.LookupByAddress(ctx, "200 N Spring St", "Los Angeles", "CA", "90001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Resolution.Method != resolver.MethodGeocoded {
+		t.Errorf("Method = %q, want geocoded", resp.Resolution.Method)
+	}
+	if !approx(resp.CombinedRate, 0.08) {
+		t.Errorf("CombinedRate = %v, want 0.08 with the district attached by parent", resp.CombinedRate)
+	}
+
+	// An address the geocoder can't match falls back to the ZIP.
+	resp, err = ts.LookupByAddress(ctx, "1 Nowhere Rd", "Beverly Hills", "CA", "90210")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Resolution.Method != resolver.MethodZIPFallback || !approx(resp.CombinedRate, 0.0925) {
+		t.Errorf("got method %q rate %v, want zip fallback at 0.0925", resp.Resolution.Method, resp.CombinedRate)
+	}
+}
+
+func TestLookupByAddress_GeocoderErrorNotCached(t *testing.T) {
+	gc := &geocodertest.Geocoder{
+		Results: map[string]*geocoder.Result{
+			"200 N Spring St": {StateFIPS: "06", CountyFIPS: "06037", PlaceFIPS: "0644000"},
+		},
+		Err: errors.New("census returned status 503"),
+	}
+	ts, _ := newTestService(t, cache.Options{TTL: time.Hour, GeocodeHitTTL: time.Hour, GeocodeMissTTL: time.Hour}, gc)
+	ctx := context.Background()
+
+	resp, err := ts.LookupByAddress(ctx, "200 N Spring St", "Los Angeles", "CA", "90001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Resolution.FallbackReason != resolver.FallbackGeocoderError {
+		t.Fatalf("FallbackReason = %q, want geocoder_error", resp.Resolution.FallbackReason)
+	}
+
+	gc.Err = nil
+	resp, err = ts.LookupByAddress(ctx, "200 N Spring St", "Los Angeles", "CA", "90001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Resolution.Method != resolver.MethodGeocoded {
+		t.Errorf("Method = %q after the geocoder recovered, want geocoded", resp.Resolution.Method)
+	}
+}
+
+func TestLookupByAddress_PartialChainFailureNotCached(t *testing.T) {
+	failing := &geocodertest.Geocoder{Err: errors.New("census returned status 503")}
+	missing := &geocodertest.Geocoder{}
+	gc := geocoder.NewChain(
+		geocoder.Provider{Name: "census", Geocoder: failing},
+		geocoder.Provider{Name: "nominatim", Geocoder: missing},
+	)
+	ts, _ := newTestService(t, cache.Options{TTL: time.Hour, GeocodeHitTTL: time.Hour, GeocodeMissTTL: time.Hour}, gc)
+	ctx := context.Background()
+
+	resp, err := ts.LookupByAddress(ctx, "200 N Spring St", "Los Angeles", "CA", "90001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Resolution.FallbackReason != resolver.FallbackGeocoderError {
+		t.Fatalf("FallbackReason = %q, want geocoder_error", resp.Resolution.FallbackReason)
+	}
+
+	// Neither the miss nor the ZIP fallback was cached, so the recovered
+	// provider is asked again.
+	failing.Err = nil
+	failing.Results = map[string]*geocoder.Result{
+		"200 N Spring St": {StateFIPS: "06", CountyFIPS: "06037", PlaceFIPS: "0644000"},
+	}
+	resp, err = ts.LookupByAddress(ctx, "200 N Spring St", "Los Angeles", "CA", "90001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Resolution.Method != resolver.MethodGeocoded {
+		t.Errorf("Method = %q after the provider recovered, want geocoded", resp.Resolution.Method)
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prashkn/sales-tax-api/internal/resolver"
+)
+
+// Confidence levels for an address lookup.
+const (
+	ConfidenceHigh   = "high"
+	ConfidenceMedium = "medium"
+	ConfidenceLow    = "low"
+)
+
+// Resolution explains how an address lookup was resolved so clients can
+// decide whether to trust the result or ask the user to confirm.
+type Resolution struct {
+	Method         string        `json:"method"`
+	FallbackReason string        `json:"fallback_reason,omitempty"`
+	Confidence     string        `json:"confidence"`
+	Match          *GeocodeMatch `json:"match,omitempty"`
+}
+
+// GeocodeMatch is the geocoder's match for an address.
+type GeocodeMatch struct {
+	Provider           string  `json:"provider,omitempty"`
+	MatchedAddress     string  `json:"matched_address,omitempty"`
+	Latitude           float64 `json:"latitude"`
+	Longitude          float64 `json:"longitude"`
+	CensusTract        string  `json:"census_tract,omitempty"`
+	CensusBlock        string  `json:"census_block,omitempty"`
+	CandidateCount     int     `json:"candidate_count"`
+	MultipleCandidates bool    `json:"multiple_candidates"`
+}
+
+// newResolution converts the resolver's outcome to its response form.
+//
+// Confidence is high for a geocoded address with a single candidate, medium
+// when the geocoder returned several candidates (the best was used), and low
+// whenever the rates came from the ZIP rather than the address.
+func newResolution(res *resolver.Resolution) *Resolution {
+	r := &Resolution{
+		Method:         res.Method,
+		FallbackReason: res.FallbackReason,
+		Confidence:     ConfidenceLow,
+	}
+
+	if g := res.Geocode; g != nil {
+		r.Match = &GeocodeMatch{
+			Provider:           g.Provider,
+			MatchedAddress:     g.MatchedAddress,
+			Latitude:           g.Latitude,
+			Longitude:          g.Longitude,
+			CensusTract:        g.CensusTract,
+			CensusBlock:        g.CensusBlock,
+			CandidateCount:     g.MatchCount,
+			MultipleCandidates: g.MatchCount > 1,
+		}
+	}
+
+	if res.Method == resolver.MethodGeocoded {
+		r.Confidence = ConfidenceHigh
+		if r.Match != nil && r.Match.MultipleCandidates {
+			r.Confidence = ConfidenceMedium
+		}
+	}
+	return r
+}
+
+// Mismatch is a supplied address field that disagrees with the ZIP or the
+// resolved jurisdictions.
+type Mismatch struct {
+	Field    string `json:"field"`
+	Supplied string `json:"supplied"`
+	Expected string `json:"expected"`
+	Source   string `json:"source"`
+	Severity string `json:"severity"`
+}
+
+// MismatchError is returned when the supplied state disagrees with the
+// state the address resolved to, so the rates would be for the wrong place.
+type MismatchError struct {
+	Mismatches []Mismatch
+}
+
+func (e *MismatchError) Error() string {
+	var parts []string
+	for _, m := range e.Mismatches {
+		if m.Severity == resolver.SeverityError {
+			parts = append(parts, fmt.Sprintf("%s %s resolved to %s", m.Field, m.Supplied, m.Expected))
+		}
+	}
+	return "address does not match resolved jurisdictions: " + strings.Join(parts, ", ")
+}
+
+// checkMismatches converts the resolver's mismatches, returning a
+// MismatchError if any is an error and the warnings otherwise.
+func checkMismatches(ms []resolver.Mismatch) ([]Mismatch, error) {
+	var out []Mismatch
+	blocking := false
+	for _, m := range ms {
+		out = append(out, Mismatch{
+			Field:    m.Field,
+			Supplied: m.Supplied,
+			Expected: m.Expected,
+			Source:   m.Source,
+			Severity: m.Severity,
+		})
+		if m.Severity == resolver.SeverityError {
+			blocking = true
+		}
+	}
+	if blocking {
+		return nil, &MismatchError{Mismatches: out}
+	}
+	return out, nil
+}
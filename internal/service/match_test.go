@@ -0,0 +1,101 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prashkn/sales-tax-api/internal/geocoder"
+	"github.com/prashkn/sales-tax-api/internal/resolver"
+)
+
+func TestNewResolution_Confidence(t *testing.T) {
+	tests := []struct {
+		name string
+		res  *resolver.Resolution
+		want string
+	}{
+		{
+			name: "single geocoder match",
+			res:  &resolver.Resolution{Method: resolver.MethodGeocoded, Geocode: &geocoder.Result{MatchCount: 1}},
+			want: ConfidenceHigh,
+		},
+		{
+			name: "multiple geocoder candidates",
+			res:  &resolver.Resolution{Method: resolver.MethodGeocoded, Geocode: &geocoder.Result{MatchCount: 3}},
+			want: ConfidenceMedium,
+		},
+		{
+			name: "zip fallback",
+			res:  &resolver.Resolution{Method: resolver.MethodZIPFallback, FallbackReason: resolver.FallbackNoMatch},
+			want: ConfidenceLow,
+		},
+		{
+			name: "geocoded but no jurisdictions on file",
+			res: &resolver.Resolution{
+				Method:         resolver.MethodZIPFallback,
+				FallbackReason: resolver.FallbackNoJurisdictions,
+				Geocode:        &geocoder.Result{MatchCount: 1},
+			},
+			want: ConfidenceLow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newResolution(tt.res)
+			if got.Confidence != tt.want {
+				t.Errorf("Confidence = %q, want %q", got.Confidence, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewResolution_Match(t *testing.T) {
+	got := newResolution(&resolver.Resolution{
+		Method: resolver.MethodGeocoded,
+		Geocode: &geocoder.Result{
+			Provider:       "census",
+			MatchedAddress: "123 MAIN ST, BEVERLY HILLS, CA, 90210",
+			Latitude:       34.07,
+			Longitude:      -118.4,
+			CensusTract:    "06037701002",
+			CensusBlock:    "060370701002014",
+			MatchCount:     2,
+		},
+	})
+
+	if got.Match == nil {
+		t.Fatal("expected match details")
+	}
+	if got.Match.Provider != "census" || got.Match.CensusTract != "06037701002" {
+		t.Errorf("unexpected match: %+v", got.Match)
+	}
+	if !got.Match.MultipleCandidates || got.Match.CandidateCount != 2 {
+		t.Errorf("expected multiple candidates, got %+v", got.Match)
+	}
+}
+
+func TestCheckMismatches(t *testing.T) {
+	warning := resolver.Mismatch{Field: "city", Supplied: "BROOKLYN", Expected: "New York City", Source: resolver.SourceJurisdictions, Severity: resolver.SeverityWarning}
+	stateErr := resolver.Mismatch{Field: "state", Supplied: "CA", Expected: "NY", Source: resolver.SourceJurisdictions, Severity: resolver.SeverityError}
+
+	warnings, err := checkMismatches([]resolver.Mismatch{warning})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].Field != "city" {
+		t.Errorf("expected the city warning, got %+v", warnings)
+	}
+
+	_, err = checkMismatches([]resolver.Mismatch{warning, stateErr})
+	var me *MismatchError
+	if !errors.As(err, &me) {
+		t.Fatalf("expected MismatchError, got %v", err)
+	}
+	if len(me.Mismatches) != 2 {
+		t.Errorf("expected both mismatches in the error, got %+v", me.Mismatches)
+	}
+	if want := "address does not match resolved jurisdictions: state CA resolved to NY"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
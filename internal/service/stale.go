@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prashkn/sales-tax-api/internal/resolver"
+)
+
+// ErrNoJurisdictions is wrapped by lookup errors for inputs that resolve to
+// no jurisdictions, as opposed to failures reaching the data.
+var ErrNoJurisdictions = errors.New("no jurisdictions found")
+
+// ErrGeocoder is wrapped by lookup errors the geocoder caused. Those that
+// also satisfy geocoder.Unavailable never reached a provider.
+var ErrGeocoder = resolver.ErrGeocoder
+
+const (
+	// recheckInterval is how often a degraded store is pinged.
+	recheckInterval = 5 * time.Second
+	// maxPendingRefreshes bounds the keys queued while the store is down;
+	// the rest refresh on their next lookup.
+	maxPendingRefreshes = 10000
+)
+
+// revalidator tracks whether the store is failing and which stale cache
+// entries to refresh once it recovers. While degraded, stale entries are
+// served without trying the store, so an outage doesn't add a failed query
+// to every request.
+type revalidator struct {
+	ping     func(context.Context) error
+	interval time.Duration
+
+	mu       sync.Mutex
+	degraded bool
+	pending  map[string]func(context.Context) error
+}
+
+func newRevalidator(ping func(context.Context) error) *revalidator {
+	return &revalidator{
+		ping:     ping,
+		interval: recheckInterval,
+		pending:  make(map[string]func(context.Context) error),
+	}
+}
+
+func (rv *revalidator) isDegraded() bool {
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+	return rv.degraded
+}
+
+// queue queues refresh for key and, on the first failure, marks the store
+// degraded and starts watching for it to recover.
+func (rv *revalidator) queue(key string, refresh func(context.Context) error) {
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+
+	if len(rv.pending) < maxPendingRefreshes {
+		rv.pending[key] = refresh
+	}
+	if !rv.degraded {
+		rv.degraded = true
+		slog.Warn("store unavailable, serving stale cache entries")
+		go rv.recover()
+	}
+}
+
+// recover pings the store until it answers, then clears the degraded flag
+// and runs the queued refreshes.
+func (rv *revalidator) recover() {
+	ticker := time.NewTicker(rv.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), rv.interval)
+		err := rv.ping(ctx)
+		cancel()
+		if err == nil {
+			break
+		}
+	}
+
+	rv.mu.Lock()
+	pending := rv.pending
+	rv.pending = make(map[string]func(context.Context) error)
+	rv.degraded = false
+	rv.mu.Unlock()
+
+	slog.Info("store recovered, refreshing stale cache entries", "count", len(pending))
+	for key, refresh := range pending {
+		if err := refresh(context.Background()); err != nil {
+			slog.Warn("refreshing stale cache entry", "key", key, "error", err)
+		}
+	}
+}
+
+// withStaleFallback resolves a cache miss or stale hit through coalesce.
+// When the store is failing and a stale copy exists, the stale copy is
+// served, marked in Meta, and the key is refreshed once the store recovers.
+func (ts *TaxService) withStaleFallback(ctx context.Context, key string, stale *TaxResponse, resolve func(context.Context) (*TaxResponse, error)) (*TaxResponse, error) {
+	refresh := func(ctx context.Context) error {
+		_, err := resolve(ts.cache.Pin(ctx))
+		return err
+	}
+
+	if stale != nil && ts.reval.isDegraded() {
+		ts.reval.queue(key, refresh)
+		return markStale(stale), nil
+	}
+
+	// Callers pinned to different versions resolve separately, so none is
+	// handed a response built from, and cached under, another version.
+	resp, err := ts.coalesce(ctx, ts.cache.PinnedVersion(ctx)+":"+key, resolve)
+	if err != nil && stale != nil && ctx.Err() == nil && isStoreFailure(err) {
+		ts.reval.queue(key, refresh)
+		return markStale(stale), nil
+	}
+	return resp, err
+}
+
+// isStoreFailure reports whether a lookup failed for lack of data access
+// rather than because of its input.
+func isStoreFailure(err error) bool {
+	var mismatch *MismatchError
+	return !errors.Is(err, ErrNoJurisdictions) && !errors.As(err, &mismatch)
+}
+
+func markStale(resp *TaxResponse) *TaxResponse {
+	resp.Meta.Stale = true
+	return resp
+}
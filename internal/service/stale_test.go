@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRevalidator_RefreshesAfterRecovery(t *testing.T) {
+	var pings atomic.Int32
+	rv := newRevalidator(func(context.Context) error {
+		if pings.Add(1) < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	rv.interval = time.Millisecond
+
+	refreshed := make(chan string, 2)
+	refresh := func(key string) func(context.Context) error {
+		return func(context.Context) error {
+			refreshed <- key
+			return nil
+		}
+	}
+	rv.queue("zip:90210", refresh("zip:90210"))
+	rv.queue("zip:10001", refresh("zip:10001"))
+	if !rv.isDegraded() {
+		t.Fatal("expected degraded after queue")
+	}
+
+	got := map[string]bool{}
+	for range 2 {
+		select {
+		case key := <-refreshed:
+			got[key] = true
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for refresh")
+		}
+	}
+	if !got["zip:90210"] || !got["zip:10001"] {
+		t.Errorf("refreshed %v, want both keys", got)
+	}
+	if rv.isDegraded() {
+		t.Error("expected degraded cleared after recovery")
+	}
+	if n := pings.Load(); n != 3 {
+		t.Errorf("pinged %d times, want 3", n)
+	}
+}
+
+func TestIsStoreFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"db error", errors.New("getting jurisdictions: connection refused"), true},
+		{"unknown zip", errZIPNotFound("00000"), false},
+		{"mismatch", &MismatchError{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isStoreFailure(tt.err); got != tt.want {
+				t.Errorf("isStoreFailure(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,9 +2,14 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
+	"golang.org/x/sync/singleflight"
+
+	"github.com/prashkn/sales-tax-api/internal/address"
 	"github.com/prashkn/sales-tax-api/internal/cache"
 	"github.com/prashkn/sales-tax-api/internal/geocoder"
 	"github.com/prashkn/sales-tax-api/internal/resolver"
@@ -12,18 +17,52 @@ import (
 )
 
 type TaxResponse struct {
-	ZIPCode       string             `json:"zip_code"`
+	ZIPCode string `json:"zip_code"`
+	RateSet
+	// Ambiguous is true when the ZIP maps to more than one jurisdiction set
+	// (e.g. it straddles a city line). The top-level rates are the primary
+	// set; Alternatives holds the others so clients can ask for an address.
+	Ambiguous    bool      `json:"ambiguous"`
+	Alternatives []RateSet `json:"alternatives,omitempty"`
+	// Address is the standardized form of the requested address, set only
+	// on address lookups so clients can store it.
+	Address *address.Address `json:"address,omitempty"`
+	// Resolution describes how an address was matched, set only on address
+	// lookups.
+	Resolution *Resolution `json:"resolution,omitempty"`
+	// Warnings lists address fields that look inconsistent with the
+	// result without being conclusive, e.g. a mailing city that differs
+	// from the taxing city.
+	Warnings []Mismatch `json:"warnings,omitempty"`
+	Meta     Meta       `json:"meta"`
+}
+
+// RateSet is the combined rate, breakdown and per-jurisdiction rates for one
+// set of jurisdictions.
+type RateSet struct {
 	CombinedRate  float64            `json:"combined_rate"`
 	Breakdown     RateBreakdown      `json:"breakdown"`
 	Jurisdictions []JurisdictionRate `json:"jurisdictions"`
-	Meta          Meta               `json:"meta"`
+	// RatesByType holds the combined rate for each non-general rate type
+	// (lodging, rental_car, ...) levied by at least one jurisdiction.
+	RatesByType map[string]TypeRate `json:"rates_by_type,omitempty"`
+}
+
+// TypeRate is the combined rate and breakdown for one rate type.
+type TypeRate struct {
+	CombinedRate float64       `json:"combined_rate"`
+	Breakdown    RateBreakdown `json:"breakdown"`
 }
 
+// RateBreakdown sums rates by category. County includes county-equivalent
+// boroughs; Subdivision covers county subdivisions and townships.
 type RateBreakdown struct {
-	State   float64 `json:"state"`
-	County  float64 `json:"county"`
-	City    float64 `json:"city"`
-	Special float64 `json:"special"`
+	State       float64 `json:"state"`
+	County      float64 `json:"county"`
+	City        float64 `json:"city"`
+	Subdivision float64 `json:"subdivision"`
+	Tribal      float64 `json:"tribal"`
+	Special     float64 `json:"special"`
 }
 
 type JurisdictionRate struct {
@@ -31,60 +70,131 @@ type JurisdictionRate struct {
 	Name     string  `json:"name"`
 	Type     string  `json:"type"`
 	Rate     float64 `json:"rate"`
+	// RatesByType holds this jurisdiction's non-general rates, each of which
+	// applies in place of Rate for that type of transaction.
+	RatesByType map[string]float64 `json:"rates_by_type,omitempty"`
+	// ParentFallback marks a special district attached because its parent
+	// county or city matched; its own boundary isn't on file, so the address
+	// may lie outside it.
+	ParentFallback bool `json:"parent_fallback,omitempty"`
+	// Administration says who collects this jurisdiction's tax and where to
+	// register when it isn't the state.
+	Administration Administration `json:"administration"`
+}
+
+// Administration describes how a jurisdiction's tax is collected.
+type Administration struct {
+	// Type is "state_administered" (filed with the state return),
+	// "self_collected" (the locality requires its own registration), or
+	// "unknown" when it hasn't been curated.
+	Type            string `json:"type"`
+	FilingAuthority string `json:"filing_authority,omitempty"`
+	RegistrationURL string `json:"registration_url,omitempty"`
+	RegistrationID  string `json:"registration_id,omitempty"`
 }
 
 type Meta struct {
 	LastUpdated string `json:"last_updated"`
 	DataVersion string `json:"data_version"`
 	Disclaimer  string `json:"disclaimer"`
+	// Stale is true when the database couldn't be reached and the
+	// response was served from an expired cache entry.
+	Stale bool `json:"stale,omitempty"`
 }
 
 type CalculateResponse struct {
-	ZIPCode   string  `json:"zip_code"`
-	Amount    float64 `json:"amount"`
-	TaxRate   float64 `json:"tax_rate"`
-	TaxAmount float64 `json:"tax_amount"`
-	Total     float64 `json:"total"`
-	Meta      Meta    `json:"meta"`
+	ZIPCode         string  `json:"zip_code"`
+	TransactionType string  `json:"transaction_type"`
+	Amount          float64 `json:"amount"`
+	TaxRate         float64 `json:"tax_rate"`
+	TaxAmount       float64 `json:"tax_amount"`
+	Total           float64 `json:"total"`
+	Meta            Meta    `json:"meta"`
+}
+
+// Cache is the response cache TaxService reads through. *cache.Cache
+// implements it; errors from Get and friends other than cache.ErrNotFound
+// and cache.ErrStale are treated as misses.
+type Cache interface {
+	// Pin fixes the dataset version for reads and writes made with the
+	// returned context.
+	Pin(ctx context.Context) context.Context
+	PinnedVersion(ctx context.Context) string
+	Get(ctx context.Context, zipCode string, dest any) error
+	Set(ctx context.Context, zipCode string, value any) error
+	SetNotFound(ctx context.Context, zipCode string) error
+	SetZIPs(ctx context.Context, responses map[string]any) error
+	GetAddress(ctx context.Context, addrKey string, dest any) error
+	SetAddress(ctx context.Context, addrKey string, value any) error
+	GetGeocode(ctx context.Context, addrKey string, dest any) error
+	SetGeocode(ctx context.Context, addrKey string, value any, matched bool) error
 }
 
 type TaxService struct {
-	store        *store.Store
-	zipResolver  *resolver.ZIPResolver
-	addrResolver *resolver.AddressResolver
-	rateResolver *resolver.RateResolver
-	cache        *cache.Cache
+	store         store.Store
+	zipResolver   *resolver.ZIPResolver
+	addrResolver  *resolver.AddressResolver
+	pointResolver *resolver.PointResolver
+	rateResolver  *resolver.RateResolver
+	cache         Cache
+	flight        singleflight.Group
+	reval         *revalidator
 }
 
-func NewTaxService(s *store.Store, c *cache.Cache, gc *geocoder.Client) *TaxService {
+// NewTaxService wires up the resolvers. gc geocodes street addresses and rv
+// resolves coordinates for point lookups.
+func NewTaxService(s store.Store, c Cache, gc geocoder.Geocoder, rv geocoder.Reverser) *TaxService {
 	return &TaxService{
-		store:        s,
-		zipResolver:  resolver.NewZIPResolver(s),
-		addrResolver: resolver.NewAddressResolver(s, gc),
-		rateResolver: resolver.NewRateResolver(s),
-		cache:        c,
+		store:         s,
+		zipResolver:   resolver.NewZIPResolver(s),
+		addrResolver:  resolver.NewAddressResolver(s, &cachedGeocoder{next: gc, cache: c}),
+		pointResolver: resolver.NewPointResolver(s, rv),
+		rateResolver:  resolver.NewRateResolver(s),
+		cache:         c,
+		reval:         newRevalidator(s.Ping),
 	}
 }
 
 func (ts *TaxService) LookupByZIP(ctx context.Context, zipCode string) (*TaxResponse, error) {
+	zipCode = resolver.FormatZIP(zipCode)
+	ctx = ts.cache.Pin(ctx)
+
 	// Try cache first.
 	var cached TaxResponse
-	if err := ts.cache.Get(ctx, zipCode, &cached); err == nil {
+	err := ts.cache.Get(ctx, zipCode, &cached)
+	switch {
+	case err == nil:
 		return &cached, nil
+	case errors.Is(err, cache.ErrNotFound):
+		return nil, errZIPNotFound(zipCode)
 	}
 
-	jurisdictions, err := ts.zipResolver.Resolve(ctx, zipCode)
+	return ts.withStaleFallback(ctx, "zip:"+zipCode, staleEntry(&cached, err), func(ctx context.Context) (*TaxResponse, error) {
+		return ts.resolveZIP(ctx, zipCode)
+	})
+}
+
+// resolveZIP builds and caches the response for a ZIP cache miss.
+func (ts *TaxService) resolveZIP(ctx context.Context, zipCode string) (*TaxResponse, error) {
+	candidates, err := ts.zipResolver.Candidates(ctx, zipCode)
 	if err != nil {
 		return nil, fmt.Errorf("resolving zip: %w", err)
 	}
-	if len(jurisdictions) == 0 {
-		return nil, fmt.Errorf("no jurisdictions found for zip %s", zipCode)
+	if len(candidates) == 0 {
+		// Remember the miss briefly so scrapers and bad client data
+		// don't reach the database on every retry.
+		_ = ts.cache.SetNotFound(ctx, zipCode)
+		return nil, errZIPNotFound(zipCode)
 	}
 
-	resp, err := ts.buildResponse(ctx, zipCode, jurisdictions)
+	resp, err := ts.buildResponse(ctx, zipCode, candidates[0])
 	if err != nil {
 		return nil, err
 	}
+	for _, alt := range candidates[1:] {
+		resp.Alternatives = append(resp.Alternatives, ts.buildRateSet(ctx, alt))
+	}
+	resp.Ambiguous = len(resp.Alternatives) > 0
 
 	// Cache the result (best-effort).
 	_ = ts.cache.Set(ctx, zipCode, resp)
@@ -92,30 +202,139 @@ func (ts *TaxService) LookupByZIP(ctx context.Context, zipCode string) (*TaxResp
 	return resp, nil
 }
 
+// LookupByAddress standardizes the address, then resolves and caches it
+// under the standardized form so spelling variants share one entry.
 func (ts *TaxService) LookupByAddress(ctx context.Context, street, city, state, zip string) (*TaxResponse, error) {
-	jurisdictions, err := ts.addrResolver.Resolve(ctx, street, city, state, zip)
+	addr := address.Normalize(street, city, state, zip)
+	ctx = ts.cache.Pin(ctx)
+
+	// Try cache first.
+	key := cache.AddressKey(addr.Street, addr.City, addr.State, addr.ZIP)
+	var cached TaxResponse
+	err := ts.cache.GetAddress(ctx, key, &cached)
+	if err == nil {
+		return &cached, nil
+	}
+
+	return ts.withStaleFallback(ctx, "addr:"+key, staleEntry(&cached, err), func(ctx context.Context) (*TaxResponse, error) {
+		return ts.resolveAddress(ctx, addr, key)
+	})
+}
+
+// resolveAddress builds and caches the response for an address cache miss.
+// key is the address's cache key.
+func (ts *TaxService) resolveAddress(ctx context.Context, addr address.Address, key string) (*TaxResponse, error) {
+	res, err := ts.addrResolver.Resolve(ctx, addr.Street, addr.City, addr.State, addr.ZIP)
 	if err != nil {
 		return nil, fmt.Errorf("resolving address: %w", err)
 	}
+	if len(res.Jurisdictions) == 0 {
+		return nil, fmt.Errorf("%w for address", ErrNoJurisdictions)
+	}
+	warnings, err := checkMismatches(res.Mismatches)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ts.buildResponse(ctx, addr.ZIP, res.Jurisdictions)
+	if err != nil {
+		return nil, err
+	}
+	for _, alt := range res.Alternatives {
+		resp.Alternatives = append(resp.Alternatives, ts.buildRateSet(ctx, alt))
+	}
+	resp.Ambiguous = len(resp.Alternatives) > 0
+	resp.Address = &addr
+	resp.Resolution = newResolution(res)
+	resp.Warnings = warnings
+
+	// Cache the result (best-effort). A ZIP fallback forced by a geocoder
+	// failure isn't cached, so the address is geocoded again once the
+	// geocoder recovers instead of keeping ZIP-level rates for the full TTL.
+	// That includes a chain where one provider failed and the rest found no
+	// match.
+	switch res.FallbackReason {
+	case resolver.FallbackGeocoderError, resolver.FallbackGeocoderDown:
+	default:
+		_ = ts.cache.SetAddress(ctx, key, resp)
+	}
+
+	return resp, nil
+}
+
+func errZIPNotFound(zipCode string) error {
+	return fmt.Errorf("%w for zip %s", ErrNoJurisdictions, zipCode)
+}
+
+// staleEntry returns cached if the cache read found it stale.
+func staleEntry(cached *TaxResponse, err error) *TaxResponse {
+	if errors.Is(err, cache.ErrStale) {
+		return cached
+	}
+	return nil
+}
+
+// resolveTimeout bounds a coalesced resolution. It runs detached from the
+// callers' deadlines, and without a bound of its own a hung query would hold
+// the flight, and every later lookup for the key, indefinitely.
+const resolveTimeout = 30 * time.Second
+
+// coalesce runs fn once for concurrent cache misses on the same key, so a
+// traffic spike on a cold ZIP costs one resolution rather than one per
+// request. fn runs detached from the caller's cancellation, bounded by
+// resolveTimeout, so one client giving up doesn't fail the others waiting
+// on it. Each caller gets its own copy of the top-level response, so it
+// can set Meta; the slices and maps inside are shared and must not be
+// modified.
+func (ts *TaxService) coalesce(ctx context.Context, key string, fn func(context.Context) (*TaxResponse, error)) (*TaxResponse, error) {
+	ch := ts.flight.DoChan(key, func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), resolveTimeout)
+		defer cancel()
+		return fn(ctx)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		resp := *res.Val.(*TaxResponse)
+		return &resp, nil
+	}
+}
+
+// LookupByPoint returns rates for a latitude/longitude. The response has no
+// ZIP code since the point is resolved directly to FIPS codes.
+func (ts *TaxService) LookupByPoint(ctx context.Context, lat, lng float64) (*TaxResponse, error) {
+	jurisdictions, err := ts.pointResolver.Resolve(ctx, lat, lng)
+	if err != nil {
+		return nil, fmt.Errorf("resolving point: %w", err)
+	}
 	if len(jurisdictions) == 0 {
-		return nil, fmt.Errorf("no jurisdictions found for address")
+		return nil, fmt.Errorf("%w for point", ErrNoJurisdictions)
 	}
-	return ts.buildResponse(ctx, zip, jurisdictions)
+	return ts.buildResponse(ctx, "", jurisdictions)
 }
 
-func (ts *TaxService) Calculate(ctx context.Context, zipCode string, amount float64) (*CalculateResponse, error) {
+// Calculate applies the rate for a transaction type (store.RateGeneral,
+// store.RateLodging, ...) to amount. Types no jurisdiction in the ZIP levies
+// are taxed at the general rate.
+func (ts *TaxService) Calculate(ctx context.Context, zipCode string, amount float64, transactionType string) (*CalculateResponse, error) {
 	taxResp, err := ts.LookupByZIP(ctx, zipCode)
 	if err != nil {
 		return nil, err
 	}
-	taxAmount := amount * taxResp.CombinedRate
+	rate := taxResp.rateFor(transactionType)
+	taxAmount := amount * rate
 	return &CalculateResponse{
-		ZIPCode:   zipCode,
-		Amount:    amount,
-		TaxRate:   taxResp.CombinedRate,
-		TaxAmount: taxAmount,
-		Total:     amount + taxAmount,
-		Meta:      taxResp.Meta,
+		ZIPCode:         zipCode,
+		TransactionType: transactionType,
+		Amount:          amount,
+		TaxRate:         rate,
+		TaxAmount:       taxAmount,
+		Total:           amount + taxAmount,
+		Meta:            taxResp.Meta,
 	}, nil
 }
 
@@ -125,39 +344,132 @@ func (ts *TaxService) GetDataFreshness(ctx context.Context) (*store.DataFreshnes
 }
 
 func (ts *TaxService) buildResponse(ctx context.Context, zipCode string, jurisdictions []store.Jurisdiction) (*TaxResponse, error) {
-	resp := &TaxResponse{
+	return &TaxResponse{
 		ZIPCode: zipCode,
+		RateSet: ts.buildRateSet(ctx, jurisdictions),
 		Meta:    ts.buildMeta(ctx),
-	}
+	}, nil
+}
 
+func (ts *TaxService) buildRateSet(ctx context.Context, jurisdictions []store.Jurisdiction) RateSet {
+	rates := make(map[string][]store.Rate, len(jurisdictions))
 	for _, j := range jurisdictions {
-		rate, err := ts.rateResolver.GetRate(ctx, j.FIPSCode)
+		rs, err := ts.rateResolver.GetRates(ctx, j.FIPSCode)
 		if err != nil {
 			continue // skip jurisdictions without active rates
 		}
+		rates[j.FIPSCode] = rs
+	}
+	return assembleRateSet(jurisdictions, rates)
+}
+
+// assembleRateSet combines each jurisdiction's active rates. The top-level
+// figures are general rates; RatesByType has a combined rate for every other
+// type any jurisdiction levies, where a jurisdiction's typed rate replaces its
+// general rate and jurisdictions without one contribute their general rate.
+// Jurisdictions with no active rates of any type are skipped.
+func assembleRateSet(jurisdictions []store.Jurisdiction, rates map[string][]store.Rate) RateSet {
+	var rs RateSet
+	var types []string
+	for _, j := range jurisdictions {
+		if len(rates[j.FIPSCode]) == 0 {
+			continue
+		}
 
 		jr := JurisdictionRate{
 			FIPSCode: j.FIPSCode,
 			Name:     j.Name,
 			Type:     j.Type,
-			Rate:     rate.Rate,
+
+			ParentFallback: j.ParentFallback,
+			Administration: administrationOf(j),
 		}
-		resp.Jurisdictions = append(resp.Jurisdictions, jr)
-
-		switch j.Type {
-		case "state":
-			resp.Breakdown.State += rate.Rate
-		case "county":
-			resp.Breakdown.County += rate.Rate
-		case "city":
-			resp.Breakdown.City += rate.Rate
-		case "special_district":
-			resp.Breakdown.Special += rate.Rate
+		for _, r := range rates[j.FIPSCode] {
+			if r.RateType == store.RateGeneral {
+				jr.Rate = r.Rate
+				continue
+			}
+			if jr.RatesByType == nil {
+				jr.RatesByType = make(map[string]float64)
+			}
+			jr.RatesByType[r.RateType] = r.Rate
+			if !slices.Contains(types, r.RateType) {
+				types = append(types, r.RateType)
+			}
 		}
+		rs.Jurisdictions = append(rs.Jurisdictions, jr)
+		rs.Breakdown.add(j.Type, jr.Rate)
 	}
+	rs.CombinedRate = rs.Breakdown.total()
 
-	resp.CombinedRate = resp.Breakdown.State + resp.Breakdown.County + resp.Breakdown.City + resp.Breakdown.Special
-	return resp, nil
+	for _, t := range types {
+		if rs.RatesByType == nil {
+			rs.RatesByType = make(map[string]TypeRate)
+		}
+		var b RateBreakdown
+		for _, jr := range rs.Jurisdictions {
+			rate, ok := jr.RatesByType[t]
+			if !ok {
+				rate = jr.Rate
+			}
+			b.add(jr.Type, rate)
+		}
+		rs.RatesByType[t] = TypeRate{CombinedRate: b.total(), Breakdown: b}
+	}
+	return rs
+}
+
+// rateFor returns the combined rate for a transaction type, falling back to
+// the general rate when no jurisdiction levies a rate of that type.
+func (rs RateSet) rateFor(rateType string) float64 {
+	if tr, ok := rs.RatesByType[rateType]; ok {
+		return tr.CombinedRate
+	}
+	return rs.CombinedRate
+}
+
+// add credits rate to the category for a jurisdiction type.
+func (b *RateBreakdown) add(jurisdictionType string, rate float64) {
+	switch jurisdictionType {
+	case "state":
+		b.State += rate
+	case "county", "borough":
+		b.County += rate
+	case "city":
+		b.City += rate
+	case "county_subdivision", "township":
+		b.Subdivision += rate
+	case "tribal_area":
+		b.Tribal += rate
+	case "special_district":
+		b.Special += rate
+	}
+}
+
+func (b RateBreakdown) total() float64 {
+	return b.State + b.County + b.City + b.Subdivision + b.Tribal + b.Special
+}
+
+func administrationOf(j store.Jurisdiction) Administration {
+	a := Administration{Type: j.Administration}
+	if a.Type == "" || a.Type == store.AdminUnknown {
+		// A state always collects its own tax. For localities, nothing
+		// curated means nothing is known: some collect their own.
+		a.Type = store.AdminUnknown
+		if j.Type == "state" {
+			a.Type = store.AdminStateAdministered
+		}
+	}
+	if j.FilingAuthority != nil {
+		a.FilingAuthority = *j.FilingAuthority
+	}
+	if j.RegistrationURL != nil {
+		a.RegistrationURL = *j.RegistrationURL
+	}
+	if j.RegistrationID != nil {
+		a.RegistrationID = *j.RegistrationID
+	}
+	return a
 }
 
 func (ts *TaxService) buildMeta(ctx context.Context) Meta {
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prashkn/sales-tax-api/internal/cache"
+	"github.com/prashkn/sales-tax-api/internal/store"
+)
+
+func TestAdministrationOf(t *testing.T) {
+	authority := "City and County of Denver Treasury"
+	url := "https://example.gov/register"
+
+	got := administrationOf(store.Jurisdiction{
+		Administration:  store.AdminSelfCollected,
+		FilingAuthority: &authority,
+		RegistrationURL: &url,
+	})
+	want := Administration{
+		Type:            store.AdminSelfCollected,
+		FilingAuthority: authority,
+		RegistrationURL: url,
+	}
+	if got != want {
+		t.Errorf("administrationOf = %+v, want %+v", got, want)
+	}
+
+	// Uncurated localities are unknown; states always collect their own.
+	if got := administrationOf(store.Jurisdiction{Type: "city", Administration: store.AdminUnknown}); got.Type != store.AdminUnknown {
+		t.Errorf("Type = %q, want %q", got.Type, store.AdminUnknown)
+	}
+	if got := administrationOf(store.Jurisdiction{Type: "state"}); got.Type != store.AdminStateAdministered {
+		t.Errorf("state Type = %q, want %q", got.Type, store.AdminStateAdministered)
+	}
+}
+
+func TestAssembleRateSet(t *testing.T) {
+	jurisdictions := []store.Jurisdiction{
+		{FIPSCode: "12", Type: "state"},
+		{FIPSCode: "12086", Type: "county"},
+		{FIPSCode: "1245000", Type: "city"},
+		{FIPSCode: "9999", Type: "special_district"}, // no active rates
+	}
+	rates := map[string][]store.Rate{
+		"12": {
+			{RateType: store.RateGeneral, Rate: 0.06},
+			{RateType: store.RateRentalCar, Rate: 0.08},
+		},
+		"12086": {
+			{RateType: store.RateGeneral, Rate: 0.01},
+			{RateType: store.RateLodging, Rate: 0.07},
+		},
+		// Levies only a lodging tax.
+		"1245000": {
+			{RateType: store.RateLodging, Rate: 0.02},
+		},
+	}
+
+	rs := assembleRateSet(jurisdictions, rates)
+
+	if len(rs.Jurisdictions) != 3 {
+		t.Fatalf("got %d jurisdictions, want 3", len(rs.Jurisdictions))
+	}
+	if !approx(rs.CombinedRate, 0.07) {
+		t.Errorf("CombinedRate = %v, want 0.07", rs.CombinedRate)
+	}
+
+	lodging := rs.RatesByType[store.RateLodging]
+	if !approx(lodging.CombinedRate, 0.06+0.07+0.02) {
+		t.Errorf("lodging CombinedRate = %v, want 0.15", lodging.CombinedRate)
+	}
+	if !approx(lodging.Breakdown.County, 0.07) || !approx(lodging.Breakdown.City, 0.02) {
+		t.Errorf("lodging Breakdown = %+v", lodging.Breakdown)
+	}
+	if got := rs.RatesByType[store.RateRentalCar].CombinedRate; !approx(got, 0.08+0.01) {
+		t.Errorf("rental_car CombinedRate = %v, want 0.09", got)
+	}
+	if _, ok := rs.RatesByType[store.RateUse]; ok {
+		t.Error("RatesByType has use, which no jurisdiction levies")
+	}
+	if got := rs.rateFor(store.RateUse); !approx(got, rs.CombinedRate) {
+		t.Errorf("rateFor(use) = %v, want general %v", got, rs.CombinedRate)
+	}
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// awaitCallers waits until n callers have reached coalesce, then briefly
+// longer so the last of them can join the flight.
+func awaitCallers(entered *atomic.Int32, n int32) {
+	for entered.Load() < n {
+		runtime.Gosched()
+	}
+	time.Sleep(20 * time.Millisecond)
+}
+
+func TestCoalesce_SharesConcurrentMisses(t *testing.T) {
+	const n = 10
+	ts := &TaxService{}
+	release := make(chan struct{})
+	var entered, calls atomic.Int32
+
+	var wg sync.WaitGroup
+	results := make([]*TaxResponse, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entered.Add(1)
+			resp, err := ts.coalesce(context.Background(), "zip:90210", func(context.Context) (*TaxResponse, error) {
+				calls.Add(1)
+				<-release
+				return &TaxResponse{ZIPCode: "90210"}, nil
+			})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			results[i] = resp
+		}()
+	}
+
+	// Let every caller join the flight before it completes.
+	awaitCallers(&entered, n)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("resolved %d times, want 1", got)
+	}
+	// Each caller gets its own copy.
+	if results[0] == results[1] {
+		t.Error("callers share one response pointer")
+	}
+}
+
+func TestCoalesce_BoundsDetachedResolution(t *testing.T) {
+	ts := &TaxService{}
+	_, err := ts.coalesce(context.Background(), "zip:10001", func(ctx context.Context) (*TaxResponse, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("detached resolution has no deadline")
+		}
+		return &TaxResponse{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCoalesce_CallerCancelDoesNotFailOthers(t *testing.T) {
+	ts := &TaxService{}
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var entered, calls atomic.Int32
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := ts.coalesce(ctx, "zip:10001", func(ctx context.Context) (*TaxResponse, error) {
+			calls.Add(1)
+			close(started)
+			<-release
+			return &TaxResponse{}, ctx.Err()
+		})
+		firstErr <- err
+	}()
+	<-started
+
+	second := make(chan error, 1)
+	go func() {
+		entered.Add(1)
+		_, err := ts.coalesce(context.Background(), "zip:10001", func(context.Context) (*TaxResponse, error) {
+			calls.Add(1)
+			return &TaxResponse{}, nil
+		})
+		second <- err
+	}()
+
+	awaitCallers(&entered, 1)
+	cancel()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled caller got %v, want context.Canceled", err)
+	}
+	close(release)
+	if err := <-second; err != nil {
+		t.Errorf("waiting caller got %v, want the shared result", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("resolved %d times, want the second caller to join the first flight", got)
+	}
+}
+
+func TestWithStaleFallback_SeparatesPinnedVersions(t *testing.T) {
+	c := cache.New(cache.NewMemory(100), cache.Options{TTL: time.Hour})
+	c.SetVersion("v1")
+	ts := &TaxService{cache: c}
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	old := c.Pin(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := ts.withStaleFallback(old, "zip:10001", nil, func(context.Context) (*TaxResponse, error) {
+			close(started)
+			<-release
+			return &TaxResponse{}, nil
+		})
+		done <- err
+	}()
+	<-started
+	defer func() {
+		close(release)
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	}()
+
+	// A caller pinned after the switch must not wait for, or share, the
+	// resolution against the old version.
+	c.SetVersion("v2")
+	current := make(chan *TaxResponse, 1)
+	go func() {
+		resp, _ := ts.withStaleFallback(c.Pin(context.Background()), "zip:10001", nil, func(context.Context) (*TaxResponse, error) {
+			return &TaxResponse{ZIPCode: "10001"}, nil
+		})
+		current <- resp
+	}()
+	select {
+	case resp := <-current:
+		if resp == nil || resp.ZIPCode != "10001" {
+			t.Errorf("got %+v, want its own resolution", resp)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("caller pinned to v2 joined the v1 flight")
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/prashkn/sales-tax-api/internal/address"
+	"github.com/prashkn/sales-tax-api/internal/store"
+)
+
+// UseTaxResponse is the use tax a buyer owes on a purchase the vendor didn't
+// (fully) tax, accrued at the ship-to location.
+type UseTaxResponse struct {
+	ZIPCode string `json:"zip_code"`
+	// Address and Resolution are set when the ship-to location included a
+	// street address.
+	Address    *address.Address `json:"address,omitempty"`
+	Resolution *Resolution      `json:"resolution,omitempty"`
+	Amount     float64          `json:"amount"`
+	// TaxPaid is the sales tax the vendor already charged, credited against
+	// the use tax due.
+	TaxPaid    float64 `json:"tax_paid"`
+	UseTaxRate float64 `json:"use_tax_rate"`
+	TaxDue     float64 `json:"tax_due"`
+	Credit     float64 `json:"credit"`
+	UseTaxOwed float64 `json:"use_tax_owed"`
+	// Ambiguous is true when the ZIP maps to more than one jurisdiction set;
+	// the primary set is used, and a street address would settle it.
+	Ambiguous     bool                 `json:"ambiguous"`
+	Jurisdictions []JurisdictionUseTax `json:"jurisdictions"`
+	Warnings      []Mismatch           `json:"warnings,omitempty"`
+	Meta          Meta                 `json:"meta"`
+}
+
+// JurisdictionUseTax is one jurisdiction's share of the use tax.
+type JurisdictionUseTax struct {
+	FIPSCode string  `json:"fips_code"`
+	Name     string  `json:"name"`
+	Type     string  `json:"type"`
+	Rate     float64 `json:"rate"`
+	TaxDue   float64 `json:"tax_due"`
+	Credit   float64 `json:"credit"`
+	Owed     float64 `json:"owed"`
+	// Administration says where to remit; self-collected localities take
+	// use tax on their own return.
+	Administration Administration `json:"administration"`
+}
+
+// UseTax resolves the ship-to location (a street address when street is set,
+// otherwise the ZIP) and computes the use tax owed on amount, crediting
+// taxPaid.
+func (ts *TaxService) UseTax(ctx context.Context, street, city, state, zip string, amount, taxPaid float64) (*UseTaxResponse, error) {
+	var taxResp *TaxResponse
+	var err error
+	if street != "" {
+		taxResp, err = ts.LookupByAddress(ctx, street, city, state, zip)
+	} else {
+		taxResp, err = ts.LookupByZIP(ctx, zip)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp := computeUseTax(taxResp.RateSet, amount, taxPaid)
+	resp.ZIPCode = taxResp.ZIPCode
+	resp.Address = taxResp.Address
+	resp.Resolution = taxResp.Resolution
+	resp.Ambiguous = taxResp.Ambiguous
+	resp.Warnings = taxResp.Warnings
+	resp.Meta = taxResp.Meta
+	return resp, nil
+}
+
+// computeUseTax applies each jurisdiction's use rate (its general rate when
+// it has none) to amount. Tax already paid is credited state first, then
+// county, municipal and district levels, the order most states apply
+// credits for tax paid to another jurisdiction. Credit beyond the tax due
+// isn't refundable.
+func computeUseTax(rs RateSet, amount, taxPaid float64) *UseTaxResponse {
+	resp := &UseTaxResponse{
+		Amount:        amount,
+		TaxPaid:       taxPaid,
+		Jurisdictions: make([]JurisdictionUseTax, len(rs.Jurisdictions)),
+	}
+
+	for i, jr := range rs.Jurisdictions {
+		rate, ok := jr.RatesByType[store.RateUse]
+		if !ok {
+			rate = jr.Rate
+		}
+		resp.Jurisdictions[i] = JurisdictionUseTax{
+			FIPSCode:       jr.FIPSCode,
+			Name:           jr.Name,
+			Type:           jr.Type,
+			Rate:           rate,
+			TaxDue:         amount * rate,
+			Administration: jr.Administration,
+		}
+		resp.UseTaxRate += rate
+	}
+
+	order := make([]int, len(resp.Jurisdictions))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return creditRank(resp.Jurisdictions[order[a]].Type) < creditRank(resp.Jurisdictions[order[b]].Type)
+	})
+
+	remaining := taxPaid
+	for _, i := range order {
+		j := &resp.Jurisdictions[i]
+		j.Credit = min(remaining, j.TaxDue)
+		j.Owed = j.TaxDue - j.Credit
+		remaining -= j.Credit
+
+		resp.TaxDue += j.TaxDue
+		resp.Credit += j.Credit
+		resp.UseTaxOwed += j.Owed
+	}
+	return resp
+}
+
+// creditRank orders jurisdiction types for applying tax-paid credits.
+func creditRank(jurisdictionType string) int {
+	switch jurisdictionType {
+	case "state":
+		return 0
+	case "county", "borough":
+		return 1
+	case "county_subdivision", "township", "city":
+		return 2
+	case "tribal_area":
+		return 3
+	default:
+		return 4
+	}
+}
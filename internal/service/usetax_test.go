@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/prashkn/sales-tax-api/internal/store"
+)
+
+func TestComputeUseTax(t *testing.T) {
+	rs := RateSet{Jurisdictions: []JurisdictionRate{
+		{FIPSCode: "08031", Type: "county", Rate: 0.01},
+		{FIPSCode: "0820000", Type: "city", Rate: 0.04, RatesByType: map[string]float64{store.RateUse: 0.035}},
+		{FIPSCode: "08", Type: "state", Rate: 0.03},
+	}}
+
+	// 3% state + 1% county + 3.5% city use tax on $1000 is $75 due. $35
+	// paid to the vendor covers the state's $30 first, then $5 of the county.
+	got := computeUseTax(rs, 1000, 35)
+
+	if !approx(got.UseTaxRate, 0.075) {
+		t.Errorf("UseTaxRate = %v, want 0.075", got.UseTaxRate)
+	}
+	if !approx(got.TaxDue, 75) || !approx(got.Credit, 35) || !approx(got.UseTaxOwed, 40) {
+		t.Errorf("TaxDue/Credit/Owed = %v/%v/%v, want 75/35/40", got.TaxDue, got.Credit, got.UseTaxOwed)
+	}
+
+	want := map[string]struct{ credit, owed float64 }{
+		"08":      {30, 0},
+		"08031":   {5, 5},
+		"0820000": {0, 35},
+	}
+	for _, j := range got.Jurisdictions {
+		w := want[j.FIPSCode]
+		if !approx(j.Credit, w.credit) || !approx(j.Owed, w.owed) {
+			t.Errorf("%s: credit/owed = %v/%v, want %v/%v", j.FIPSCode, j.Credit, j.Owed, w.credit, w.owed)
+		}
+	}
+
+	// Credit beyond the tax due isn't refunded.
+	if got := computeUseTax(rs, 1000, 100); got.UseTaxOwed != 0 || !approx(got.Credit, 75) {
+		t.Errorf("overpaid: Owed = %v, Credit = %v, want 0 and 75", got.UseTaxOwed, got.Credit)
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prashkn/sales-tax-api/internal/store"
+)
+
+// WarmProgress reports how far a cache warm-up has got.
+type WarmProgress struct {
+	Done   int // ZIPs processed
+	Total  int // ZIPs with active mappings when the warm-up started
+	Cached int // responses written
+}
+
+// WarmCache precomputes the ZIP lookup response for every ZIP with active
+// mappings and writes them to the cache, batchSize ZIPs at a time. Each
+// batch costs three queries and one pipelined cache write, however many
+// jurisdictions it touches. progress, if non-nil, is called after each
+// batch.
+func (ts *TaxService) WarmCache(ctx context.Context, batchSize int, progress func(WarmProgress)) (WarmProgress, error) {
+	ctx = ts.cache.Pin(ctx)
+
+	var p WarmProgress
+	total, err := ts.store.CountActiveZIPs(ctx)
+	if err != nil {
+		return p, err
+	}
+	p.Total = total
+	meta := ts.buildMeta(ctx)
+
+	var after string
+	for {
+		zips, err := ts.store.ListActiveZIPs(ctx, after, batchSize)
+		if err != nil {
+			return p, err
+		}
+		if len(zips) == 0 {
+			return p, nil
+		}
+		after = zips[len(zips)-1]
+
+		responses, err := ts.warmBatch(ctx, zips, meta)
+		if err != nil {
+			return p, err
+		}
+		if err := ts.cache.SetZIPs(ctx, responses); err != nil {
+			return p, fmt.Errorf("writing cache: %w", err)
+		}
+
+		p.Done += len(zips)
+		p.Cached += len(responses)
+		if progress != nil {
+			progress(p)
+		}
+	}
+}
+
+// warmBatch builds the responses for a batch of ZIPs, the same ones
+// LookupByZIP would return.
+func (ts *TaxService) warmBatch(ctx context.Context, zips []string, meta Meta) (map[string]any, error) {
+	candidates, err := ts.zipResolver.CandidatesForZIPs(ctx, zips)
+	if err != nil {
+		return nil, fmt.Errorf("resolving zips: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var fipsCodes []string
+	for _, sets := range candidates {
+		for _, set := range sets {
+			for _, j := range set {
+				if !seen[j.FIPSCode] {
+					seen[j.FIPSCode] = true
+					fipsCodes = append(fipsCodes, j.FIPSCode)
+				}
+			}
+		}
+	}
+	rates, err := ts.rateResolver.GetRatesForFIPSCodes(ctx, fipsCodes)
+	if err != nil {
+		return nil, fmt.Errorf("loading rates: %w", err)
+	}
+
+	responses := make(map[string]any, len(candidates))
+	for zip, sets := range candidates {
+		if len(sets) == 0 {
+			continue
+		}
+		responses[zip] = zipResponse(zip, sets, rates, meta)
+	}
+	return responses, nil
+}
+
+// zipResponse assembles a ZIP lookup response from preloaded rates.
+func zipResponse(zipCode string, candidates [][]store.Jurisdiction, rates map[string][]store.Rate, meta Meta) *TaxResponse {
+	resp := &TaxResponse{
+		ZIPCode: zipCode,
+		RateSet: assembleRateSet(candidates[0], rates),
+		Meta:    meta,
+	}
+	for _, alt := range candidates[1:] {
+		resp.Alternatives = append(resp.Alternatives, assembleRateSet(alt, rates))
+	}
+	resp.Ambiguous = len(resp.Alternatives) > 0
+	return resp
+}
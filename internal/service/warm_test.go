@@ -0,0 +1,33 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/prashkn/sales-tax-api/internal/store"
+)
+
+func TestZIPResponse_MatchesLookupShape(t *testing.T) {
+	state := store.Jurisdiction{FIPSCode: "06", Type: "state"}
+	cityA := store.Jurisdiction{FIPSCode: "0644000", Type: "city"}
+	cityB := store.Jurisdiction{FIPSCode: "0666000", Type: "city"}
+	rates := map[string][]store.Rate{
+		"06":      {{RateType: store.RateGeneral, Rate: 0.0725}},
+		"0644000": {{RateType: store.RateGeneral, Rate: 0.0225}},
+		"0666000": {{RateType: store.RateGeneral, Rate: 0.01}},
+	}
+
+	resp := zipResponse("90000", [][]store.Jurisdiction{{state, cityA}, {state, cityB}}, rates, Meta{DataVersion: "2026-Q4"})
+
+	if !resp.Ambiguous || len(resp.Alternatives) != 1 {
+		t.Fatalf("Ambiguous = %v with %d alternatives, want true with 1", resp.Ambiguous, len(resp.Alternatives))
+	}
+	if !approx(resp.CombinedRate, 0.095) {
+		t.Errorf("CombinedRate = %v, want the primary set's 0.095", resp.CombinedRate)
+	}
+	if !approx(resp.Alternatives[0].CombinedRate, 0.0825) {
+		t.Errorf("alternative CombinedRate = %v, want 0.0825", resp.Alternatives[0].CombinedRate)
+	}
+	if resp.ZIPCode != "90000" || resp.Meta.DataVersion != "2026-Q4" {
+		t.Errorf("ZIPCode/Meta = %q/%+v", resp.ZIPCode, resp.Meta)
+	}
+}
@@ -9,6 +9,41 @@ type Jurisdiction struct {
 	StateFIPS     string    `json:"state_fips"`
 	ParentFIPS    *string   `json:"parent_fips,omitempty"`
 	EffectiveDate time.Time `json:"effective_date"`
+
+	// Administration is AdminStateAdministered, AdminSelfCollected, or
+	// AdminUnknown when nobody has curated it. The filing authority and
+	// registration details are set for self-collected jurisdictions, where
+	// sellers register with the locality directly.
+	Administration  string  `json:"administration"`
+	FilingAuthority *string `json:"filing_authority,omitempty"`
+	RegistrationURL *string `json:"registration_url,omitempty"`
+	RegistrationID  *string `json:"registration_id,omitempty"`
+
+	// ParentFallback is set on special districts attached because their
+	// parent matched, not because the location is inside them.
+	ParentFallback bool `json:"parent_fallback,omitempty"`
+}
+
+// Administration values.
+const (
+	AdminStateAdministered = "state_administered"
+	AdminSelfCollected     = "self_collected"
+	AdminUnknown           = "unknown"
+)
+
+// Special district boundary types: what locates a district.
+const (
+	BoundaryParent  = "parent"  // nothing; attached by parent
+	BoundaryZIP4    = "zip4"    // ZIP+4 ranges
+	BoundaryPolygon = "polygon" // a polygon in the boundary files
+)
+
+// scanFields returns scan destinations in jurisdictionColumns order.
+func (j *Jurisdiction) scanFields() []any {
+	return []any{
+		&j.FIPSCode, &j.Name, &j.Type, &j.StateFIPS, &j.ParentFIPS, &j.EffectiveDate,
+		&j.Administration, &j.FilingAuthority, &j.RegistrationURL, &j.RegistrationID,
+	}
 }
 
 type Rate struct {
@@ -21,6 +56,20 @@ type Rate struct {
 	Source        string    `json:"source"`
 }
 
+// Rate types. A jurisdiction's rate of a given type is the whole rate it
+// levies on that kind of transaction; where it has none, its general rate
+// applies.
+const (
+	RateGeneral      = "general"
+	RateLodging      = "lodging"
+	RateRentalCar    = "rental_car"
+	RatePreparedFood = "prepared_food"
+	RateUse          = "use"
+)
+
+// RateTypes lists the supported rate types, general first.
+var RateTypes = []string{RateGeneral, RateLodging, RateRentalCar, RatePreparedFood, RateUse}
+
 type ZIPJurisdiction struct {
 	ZIPCode       string    `json:"zip_code"`
 	FIPSCode      string    `json:"fips_code"`
@@ -29,6 +78,27 @@ type ZIPJurisdiction struct {
 	ExpiryDate    *time.Time `json:"expiry_date,omitempty"`
 }
 
+// ZIPMatch is a jurisdiction mapped to a 5-digit ZIP, along with whether
+// the mapping is flagged as primary for that ZIP.
+type ZIPMatch struct {
+	Jurisdiction
+	IsPrimary bool `json:"is_primary"`
+}
+
+// ZIP4Jurisdiction maps an inclusive plus-4 range within a ZIP to one
+// jurisdiction. Plus4Low and Plus4High are zero-padded 4-digit strings.
+type ZIP4Jurisdiction struct {
+	ZIPCode       string     `json:"zip_code"`
+	Plus4Low      string     `json:"plus4_low"`
+	Plus4High     string     `json:"plus4_high"`
+	FIPSCode      string     `json:"fips_code"`
+	EffectiveDate time.Time  `json:"effective_date"`
+	ExpiryDate    *time.Time `json:"expiry_date,omitempty"`
+}
+
+// DatasetVersionNone is the dataset version before any promotion.
+const DatasetVersionNone = "0"
+
 // DataFreshness holds the age of the most recently updated data.
 type DataFreshness struct {
 	LastUpdated time.Time
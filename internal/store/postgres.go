@@ -2,92 +2,224 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync/atomic"
+	"time"
 
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-type Store struct {
-	pool *pgxpool.Pool
+// Postgres is the Store backed by the database the pipeline loads. Lookups
+// go to read replicas when there are any, keeping the primary for writes.
+type Postgres struct {
+	primary  *pgxpool.Pool
+	replicas []*replica
+	maxLag   time.Duration
+	next     atomic.Uint64 // rotates among equally loaded replicas
 }
 
-func New(ctx context.Context, databaseURL string) (*Store, error) {
+// Options configures read replicas for New.
+type Options struct {
+	// ReplicaURLs are read replicas lookups are spread across.
+	ReplicaURLs []string
+	// MaxReplicaLag takes a replica out of rotation while its replay lags
+	// the primary by more than this. Zero means no limit.
+	MaxReplicaLag time.Duration
+}
+
+// New connects to the primary at databaseURL and to each read replica.
+// Replicas start out of rotation and join once GetDatasetVersion finds
+// them caught up, so a replica that can't be reached at startup doesn't
+// fail New.
+func New(ctx context.Context, databaseURL string, opts Options) (*Postgres, error) {
 	pool, err := pgxpool.New(ctx, databaseURL)
 	if err != nil {
 		return nil, fmt.Errorf("connecting to database: %w", err)
 	}
 
 	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
 		return nil, fmt.Errorf("pinging database: %w", err)
 	}
 
-	return &Store{pool: pool}, nil
+	s := &Postgres{primary: pool, maxLag: opts.MaxReplicaLag}
+	for _, url := range opts.ReplicaURLs {
+		r, err := newReplica(ctx, url)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		if err := r.pool.Ping(ctx); err != nil {
+			r.markDown(err)
+		}
+		s.replicas = append(s.replicas, r)
+	}
+	return s, nil
 }
 
-func (s *Store) Close() {
-	s.pool.Close()
+func (s *Postgres) Close() {
+	s.primary.Close()
+	for _, r := range s.replicas {
+		r.pool.Close()
+	}
 }
 
-func (s *Store) Ping(ctx context.Context) error {
-	return s.pool.Ping(ctx)
+// Ping checks the primary. Replica health is reported by Replicas and
+// doesn't fail the check: lookups fall back to the primary.
+func (s *Postgres) Ping(ctx context.Context) error {
+	return s.primary.Ping(ctx)
 }
 
-func (s *Store) GetJurisdictionsByZIP(ctx context.Context, zip string) ([]Jurisdiction, error) {
-	query, args, err := jurisdictionsByZIPQuery(zip).ToSql()
+// GetZIPMatches returns every active jurisdiction mapped to a 5-digit ZIP,
+// primary mappings first. A ZIP that straddles a boundary returns the
+// jurisdictions on both sides; callers group them into candidate sets.
+func (s *Postgres) GetZIPMatches(ctx context.Context, zip string) ([]ZIPMatch, error) {
+	query, args, err := zipMatchesQuery(zip).ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("building query: %w", err)
 	}
 
-	rows, err := s.pool.Query(ctx, query, args...)
+	rows, err := s.query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("querying jurisdictions: %w", err)
 	}
 	defer rows.Close()
 
-	var jurisdictions []Jurisdiction
+	var matches []ZIPMatch
 	for rows.Next() {
-		var j Jurisdiction
-		if err := rows.Scan(&j.FIPSCode, &j.Name, &j.Type, &j.StateFIPS, &j.ParentFIPS, &j.EffectiveDate); err != nil {
+		var m ZIPMatch
+		if err := rows.Scan(append(m.scanFields(), &m.IsPrimary)...); err != nil {
 			return nil, fmt.Errorf("scanning jurisdiction: %w", err)
 		}
-		jurisdictions = append(jurisdictions, j)
+		matches = append(matches, m)
 	}
-	return jurisdictions, rows.Err()
+	return matches, rows.Err()
 }
 
-// GetJurisdictionsByFIPSCodes returns jurisdictions matching the given FIPS
-// codes, plus any special districts that are children of the matched codes.
-func (s *Store) GetJurisdictionsByFIPSCodes(ctx context.Context, fipsCodes []string) ([]Jurisdiction, error) {
-	query, args, err := jurisdictionsWithChildrenQuery(fipsCodes).ToSql()
+// GetZIPMatchesForZIPs is GetZIPMatches for a batch of 5-digit ZIPs, keyed
+// by ZIP.
+func (s *Postgres) GetZIPMatchesForZIPs(ctx context.Context, zips []string) (map[string][]ZIPMatch, error) {
+	query, args, err := zipMatchesForZIPsQuery(zips).ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("building query: %w", err)
 	}
 
-	rows, err := s.pool.Query(ctx, query, args...)
+	rows, err := s.query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("querying jurisdictions by fips: %w", err)
+		return nil, fmt.Errorf("querying jurisdictions: %w", err)
 	}
 	defer rows.Close()
 
-	var jurisdictions []Jurisdiction
+	matches := make(map[string][]ZIPMatch, len(zips))
 	for rows.Next() {
-		var j Jurisdiction
-		if err := rows.Scan(&j.FIPSCode, &j.Name, &j.Type, &j.StateFIPS, &j.ParentFIPS, &j.EffectiveDate); err != nil {
+		var m ZIPMatch
+		var zip string
+		if err := rows.Scan(append(m.scanFields(), &m.IsPrimary, &zip)...); err != nil {
 			return nil, fmt.Errorf("scanning jurisdiction: %w", err)
 		}
-		jurisdictions = append(jurisdictions, j)
+		matches[zip] = append(matches[zip], m)
 	}
-	return jurisdictions, rows.Err()
+	return matches, rows.Err()
+}
+
+// ListActiveZIPs returns up to limit ZIPs with active mappings, in order,
+// after the given ZIP. Pass "" for the first page.
+func (s *Postgres) ListActiveZIPs(ctx context.Context, after string, limit int) ([]string, error) {
+	query, args, err := activeZIPsQuery(after, limit).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building query: %w", err)
+	}
+
+	rows, err := s.query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying zip codes: %w", err)
+	}
+	zips, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("scanning zip codes: %w", err)
+	}
+	return zips, nil
+}
+
+// CountActiveZIPs returns the number of ZIPs with active mappings.
+func (s *Postgres) CountActiveZIPs(ctx context.Context) (int, error) {
+	query, args, err := countActiveZIPsQuery().ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("building query: %w", err)
+	}
+
+	var n int
+	if err := s.queryRow(ctx, query, args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("counting zip codes: %w", err)
+	}
+	return n, nil
+}
+
+// GetJurisdictionsByZIP4 returns the jurisdictions for the plus-4 range that
+// contains the given extension. An empty result means the ZIP has no +4
+// ranges covering it and callers should fall back to the 5-digit mapping.
+func (s *Postgres) GetJurisdictionsByZIP4(ctx context.Context, zip, plus4 string) ([]Jurisdiction, error) {
+	query, args, err := jurisdictionsByZIP4Query(zip, plus4).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building query: %w", err)
+	}
+
+	rows, err := s.query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying zip+4 jurisdictions: %w", err)
+	}
+	return collectJurisdictions(rows)
 }
 
-func (s *Store) GetRateByFIPS(ctx context.Context, fipsCode string) (*Rate, error) {
+// GetJurisdictionsByFIPSCodes returns the jurisdictions matching the given
+// FIPS codes.
+func (s *Postgres) GetJurisdictionsByFIPSCodes(ctx context.Context, fipsCodes []string) ([]Jurisdiction, error) {
+	query, args, err := jurisdictionsByFIPSCodesQuery(fipsCodes).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building query: %w", err)
+	}
+
+	rows, err := s.query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying jurisdictions by fips: %w", err)
+	}
+	return collectJurisdictions(rows)
+}
+
+// GetFallbackDistricts returns the special districts under the given parent
+// FIPS codes with one of the given boundary types, marked ParentFallback.
+// Callers pass the types the request had no data to locate.
+func (s *Postgres) GetFallbackDistricts(ctx context.Context, parentFIPS, boundaryTypes []string) ([]Jurisdiction, error) {
+	query, args, err := fallbackDistrictsQuery(parentFIPS, boundaryTypes).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building query: %w", err)
+	}
+
+	rows, err := s.query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying fallback districts: %w", err)
+	}
+	districts, err := collectJurisdictions(rows)
+	if err != nil {
+		return nil, err
+	}
+	for i := range districts {
+		districts[i].ParentFallback = true
+	}
+	return districts, nil
+}
+
+func (s *Postgres) GetRateByFIPS(ctx context.Context, fipsCode string) (*Rate, error) {
 	query, args, err := rateByFIPSQuery(fipsCode).ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("building query: %w", err)
 	}
 
 	var r Rate
-	err = s.pool.QueryRow(ctx, query, args...).Scan(
+	err = s.queryRow(ctx, query, args...).Scan(
 		&r.ID, &r.FIPSCode, &r.Rate, &r.RateType, &r.EffectiveDate, &r.ExpiryDate, &r.Source,
 	)
 	if err != nil {
@@ -96,27 +228,111 @@ func (s *Store) GetRateByFIPS(ctx context.Context, fipsCode string) (*Rate, erro
 	return &r, nil
 }
 
-func (s *Store) GetDataFreshness(ctx context.Context) (*DataFreshness, error) {
+// GetRatesByFIPS returns a jurisdiction's active rate for each rate type it
+// levies, ordered by rate type.
+func (s *Postgres) GetRatesByFIPS(ctx context.Context, fipsCode string) ([]Rate, error) {
+	query, args, err := activeRatesByFIPSQuery(fipsCode).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building query: %w", err)
+	}
+
+	rows, err := s.query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying rates: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []Rate
+	for rows.Next() {
+		var r Rate
+		if err := rows.Scan(&r.ID, &r.FIPSCode, &r.Rate, &r.RateType, &r.EffectiveDate, &r.ExpiryDate, &r.Source); err != nil {
+			return nil, fmt.Errorf("scanning rate: %w", err)
+		}
+		rates = append(rates, r)
+	}
+	return rates, rows.Err()
+}
+
+// GetActiveRatesByFIPSCodes is GetRatesByFIPS for a batch of
+// jurisdictions, keyed by FIPS code.
+func (s *Postgres) GetActiveRatesByFIPSCodes(ctx context.Context, fipsCodes []string) (map[string][]Rate, error) {
+	query, args, err := activeRatesByFIPSCodesQuery(fipsCodes).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building query: %w", err)
+	}
+
+	rows, err := s.query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying rates: %w", err)
+	}
+	defer rows.Close()
+
+	rates := make(map[string][]Rate, len(fipsCodes))
+	for rows.Next() {
+		var r Rate
+		if err := rows.Scan(&r.ID, &r.FIPSCode, &r.Rate, &r.RateType, &r.EffectiveDate, &r.ExpiryDate, &r.Source); err != nil {
+			return nil, fmt.Errorf("scanning rate: %w", err)
+		}
+		rates[r.FIPSCode] = append(rates[r.FIPSCode], r)
+	}
+	return rates, rows.Err()
+}
+
+func (s *Postgres) GetDataFreshness(ctx context.Context) (*DataFreshness, error) {
 	query, args, err := dataFreshnessQuery().ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("building query: %w", err)
 	}
 
 	var df DataFreshness
-	err = s.pool.QueryRow(ctx, query, args...).Scan(&df.LastUpdated, &df.RecordCount)
+	err = s.queryRow(ctx, query, args...).Scan(&df.LastUpdated, &df.RecordCount)
 	if err != nil {
 		return nil, fmt.Errorf("querying data freshness: %w", err)
 	}
 	return &df, nil
 }
 
-func (s *Store) GetRatesByFIPSCodes(ctx context.Context, fipsCodes []string) ([]Rate, error) {
+// GetDatasetVersion returns the version of the latest promotion, or
+// DatasetVersionNone when nothing has been promoted. It reads the primary,
+// then checks each replica against it: a replica that hasn't replayed the
+// promotion yet, or lags too far, gets no lookups until a later call finds
+// it caught up. The cache polls this before switching namespaces, so
+// responses cached under a new version are never built from a replica's
+// older rates.
+func (s *Postgres) GetDatasetVersion(ctx context.Context) (string, error) {
+	version, err := datasetVersion(ctx, s.primary)
+	if err != nil {
+		return "", err
+	}
+	s.checkReplicas(ctx, version)
+	return version, nil
+}
+
+// datasetVersion reads the latest promoted version from one pool.
+func datasetVersion(ctx context.Context, pool *pgxpool.Pool) (string, error) {
+	query, args, err := datasetVersionQuery().ToSql()
+	if err != nil {
+		return "", fmt.Errorf("building query: %w", err)
+	}
+
+	var version string
+	err = pool.QueryRow(ctx, query, args...).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return DatasetVersionNone, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("querying dataset version: %w", err)
+	}
+	return version, nil
+}
+
+func (s *Postgres) GetRatesByFIPSCodes(ctx context.Context, fipsCodes []string) ([]Rate, error) {
 	query, args, err := ratesByFIPSCodesQuery(fipsCodes).ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("building query: %w", err)
 	}
 
-	rows, err := s.pool.Query(ctx, query, args...)
+	rows, err := s.query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("querying rates: %w", err)
 	}
@@ -131,4 +347,98 @@ func (s *Store) GetRatesByFIPSCodes(ctx context.Context, fipsCodes []string) ([]
 		rates = append(rates, r)
 	}
 	return rates, rows.Err()
-}
\ No newline at end of file
+}
+
+// collectJurisdictions scans rows selected with the standard jurisdiction
+// column list and closes them.
+func collectJurisdictions(rows pgx.Rows) ([]Jurisdiction, error) {
+	defer rows.Close()
+
+	var jurisdictions []Jurisdiction
+	for rows.Next() {
+		var j Jurisdiction
+		if err := rows.Scan(j.scanFields()...); err != nil {
+			return nil, fmt.Errorf("scanning jurisdiction: %w", err)
+		}
+		jurisdictions = append(jurisdictions, j)
+	}
+	return jurisdictions, rows.Err()
+}
+
+// Snapshot exports the data lookups read, in one read-only transaction so
+// the tables agree with each other and the dataset version.
+func (s *Postgres) Snapshot(ctx context.Context) (*Snapshot, error) {
+	tx, err := s.primary.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("beginning snapshot transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	snap := &Snapshot{Format: SnapshotFormat, ExportedAt: time.Now().UTC()}
+
+	query, args, err := datasetVersionQuery().ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building query: %w", err)
+	}
+	err = tx.QueryRow(ctx, query, args...).Scan(&snap.DatasetVersion)
+	if errors.Is(err, pgx.ErrNoRows) {
+		snap.DatasetVersion = DatasetVersionNone
+	} else if err != nil {
+		return nil, fmt.Errorf("querying dataset version: %w", err)
+	}
+
+	query, args, err = dataFreshnessQuery().ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building query: %w", err)
+	}
+	var count int
+	if err := tx.QueryRow(ctx, query, args...).Scan(&snap.LastUpdated, &count); err != nil {
+		return nil, fmt.Errorf("querying data freshness: %w", err)
+	}
+
+	if snap.Jurisdictions, err = collectSnapshot(ctx, tx, snapshotJurisdictionsQuery(), func(j *SnapshotJurisdiction) []any {
+		return append(j.scanFields(), &j.BoundaryType)
+	}); err != nil {
+		return nil, fmt.Errorf("exporting jurisdictions: %w", err)
+	}
+	if snap.Rates, err = collectSnapshot(ctx, tx, snapshotRatesQuery(), func(r *Rate) []any {
+		return []any{&r.ID, &r.FIPSCode, &r.Rate, &r.RateType, &r.EffectiveDate, &r.ExpiryDate, &r.Source}
+	}); err != nil {
+		return nil, fmt.Errorf("exporting rates: %w", err)
+	}
+	if snap.ZIPs, err = collectSnapshot(ctx, tx, snapshotZIPsQuery(), func(z *ZIPJurisdiction) []any {
+		return []any{&z.ZIPCode, &z.FIPSCode, &z.IsPrimary, &z.EffectiveDate, &z.ExpiryDate}
+	}); err != nil {
+		return nil, fmt.Errorf("exporting zip mappings: %w", err)
+	}
+	if snap.ZIP4s, err = collectSnapshot(ctx, tx, snapshotZIP4sQuery(), func(z *ZIP4Jurisdiction) []any {
+		return []any{&z.ZIPCode, &z.Plus4Low, &z.Plus4High, &z.FIPSCode, &z.EffectiveDate, &z.ExpiryDate}
+	}); err != nil {
+		return nil, fmt.Errorf("exporting zip+4 mappings: %w", err)
+	}
+	return snap, nil
+}
+
+// collectSnapshot runs q in tx and scans every row into a T through fields.
+func collectSnapshot[T any](ctx context.Context, tx pgx.Tx, q sq.SelectBuilder, fields func(*T) []any) ([]T, error) {
+	query, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("building query: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []T
+	for rows.Next() {
+		var v T
+		if err := rows.Scan(fields(&v)...); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
@@ -4,9 +4,22 @@ import sq "github.com/Masterminds/squirrel"
 
 var psql = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-func jurisdictionsByZIPQuery(zip string) sq.SelectBuilder {
+// jurisdictionColumns lists the columns scanned by Jurisdiction.scanFields,
+// qualified with prefix (e.g. "j.") for joins.
+func jurisdictionColumns(prefix string) []string {
+	cols := []string{
+		"fips_code", "name", "type", "state_fips", "parent_fips", "effective_date",
+		"administration", "filing_authority", "registration_url", "registration_id",
+	}
+	for i, c := range cols {
+		cols[i] = prefix + c
+	}
+	return cols
+}
+
+func zipMatchesQuery(zip string) sq.SelectBuilder {
 	return psql.
-		Select("j.fips_code", "j.name", "j.type", "j.state_fips", "j.parent_fips", "j.effective_date").
+		Select(append(jurisdictionColumns("j."), "z.is_primary")...).
 		From("zip_to_jurisdictions z").
 		Join("jurisdictions j ON j.fips_code = z.fips_code").
 		Where(sq.Eq{"z.zip_code": zip}).
@@ -14,6 +27,52 @@ func jurisdictionsByZIPQuery(zip string) sq.SelectBuilder {
 		OrderBy("z.is_primary DESC")
 }
 
+// zipMatchesForZIPsQuery is zipMatchesQuery for a batch of ZIPs, with the
+// ZIP as the last column.
+func zipMatchesForZIPsQuery(zips []string) sq.SelectBuilder {
+	return psql.
+		Select(append(jurisdictionColumns("j."), "z.is_primary", "z.zip_code")...).
+		From("zip_to_jurisdictions z").
+		Join("jurisdictions j ON j.fips_code = z.fips_code").
+		Where(sq.Eq{"z.zip_code": zips}).
+		Where("z.expiry_date IS NULL").
+		OrderBy("z.zip_code", "z.is_primary DESC")
+}
+
+// activeZIPsQuery pages through ZIPs with active mappings in order, starting
+// after the given ZIP.
+func activeZIPsQuery(after string, limit int) sq.SelectBuilder {
+	return psql.
+		Select("DISTINCT zip_code").
+		From("zip_to_jurisdictions").
+		Where("expiry_date IS NULL").
+		Where(sq.Gt{"zip_code": after}).
+		OrderBy("zip_code").
+		Limit(uint64(limit))
+}
+
+func countActiveZIPsQuery() sq.SelectBuilder {
+	return psql.
+		Select("COUNT(DISTINCT zip_code)").
+		From("zip_to_jurisdictions").
+		Where("expiry_date IS NULL")
+}
+
+// jurisdictionsByZIP4Query returns the jurisdictions mapped to the plus-4
+// range containing the given extension. Extensions are fixed-width digit
+// strings, so text comparison matches numeric order.
+func jurisdictionsByZIP4Query(zip, plus4 string) sq.SelectBuilder {
+	return psql.
+		Select(jurisdictionColumns("j.")...).
+		From("zip4_to_jurisdictions z").
+		Join("jurisdictions j ON j.fips_code = z.fips_code").
+		Where(sq.Eq{"z.zip_code": zip}).
+		Where(sq.LtOrEq{"z.plus4_low": plus4}).
+		Where(sq.GtOrEq{"z.plus4_high": plus4}).
+		Where("z.expiry_date IS NULL").
+		OrderBy("j.type")
+}
+
 func rateByFIPSQuery(fipsCode string) sq.SelectBuilder {
 	return psql.
 		Select("id", "fips_code", "rate", "rate_type", "effective_date", "expiry_date", "source").
@@ -25,6 +84,30 @@ func rateByFIPSQuery(fipsCode string) sq.SelectBuilder {
 		Limit(1)
 }
 
+// activeRatesByFIPSQuery returns the newest active rate of each rate type
+// for a jurisdiction.
+func activeRatesByFIPSQuery(fipsCode string) sq.SelectBuilder {
+	return psql.
+		Select("id", "fips_code", "rate", "rate_type", "effective_date", "expiry_date", "source").
+		Options("DISTINCT ON (rate_type)").
+		From("rates").
+		Where(sq.Eq{"fips_code": fipsCode}).
+		Where("expiry_date IS NULL").
+		OrderBy("rate_type", "effective_date DESC")
+}
+
+// activeRatesByFIPSCodesQuery is activeRatesByFIPSQuery for a batch of
+// jurisdictions.
+func activeRatesByFIPSCodesQuery(fipsCodes []string) sq.SelectBuilder {
+	return psql.
+		Select("id", "fips_code", "rate", "rate_type", "effective_date", "expiry_date", "source").
+		Options("DISTINCT ON (fips_code, rate_type)").
+		From("rates").
+		Where(sq.Eq{"fips_code": fipsCodes}).
+		Where("expiry_date IS NULL").
+		OrderBy("fips_code", "rate_type", "effective_date DESC")
+}
+
 func ratesByFIPSCodesQuery(fipsCodes []string) sq.SelectBuilder {
 	return psql.
 		Select("id", "fips_code", "rate", "rate_type", "effective_date", "expiry_date", "source").
@@ -35,25 +118,83 @@ func ratesByFIPSCodesQuery(fipsCodes []string) sq.SelectBuilder {
 		OrderBy("fips_code", "effective_date DESC")
 }
 
-// jurisdictionsWithChildrenQuery returns jurisdictions matching the given FIPS
-// codes plus any special_district children whose parent_fips matches one of
-// the given codes (so geocoded lookups pick up special districts too).
-func jurisdictionsWithChildrenQuery(fipsCodes []string) sq.SelectBuilder {
+// jurisdictionsByFIPSCodesQuery returns the jurisdictions matching the
+// given FIPS codes exactly.
+func jurisdictionsByFIPSCodesQuery(fipsCodes []string) sq.SelectBuilder {
 	return psql.
-		Select("fips_code", "name", "type", "state_fips", "parent_fips", "effective_date").
+		Select(jurisdictionColumns("")...).
 		From("jurisdictions").
-		Where(sq.Or{
-			sq.Eq{"fips_code": fipsCodes},
-			sq.And{
-				sq.Eq{"type": "special_district"},
-				sq.Eq{"parent_fips": fipsCodes},
-			},
-		}).
+		Where(sq.Eq{"fips_code": fipsCodes}).
 		OrderBy("type")
 }
 
+// fallbackDistrictsQuery returns the special districts under the given
+// parents whose boundary type is one of boundaryTypes.
+func fallbackDistrictsQuery(parentFIPS, boundaryTypes []string) sq.SelectBuilder {
+	return psql.
+		Select(jurisdictionColumns("")...).
+		From("jurisdictions").
+		Where(sq.Eq{"type": "special_district"}).
+		Where(sq.Eq{"boundary_type": boundaryTypes}).
+		Where(sq.Eq{"parent_fips": parentFIPS}).
+		OrderBy("fips_code")
+}
+
 func dataFreshnessQuery() sq.SelectBuilder {
 	return psql.
 		Select("COALESCE(MAX(updated_at), NOW())", "COUNT(*)").
 		From("jurisdictions")
 }
+
+// datasetVersionQuery returns the most recently promoted dataset version.
+func datasetVersionQuery() sq.SelectBuilder {
+	return psql.
+		Select("version").
+		From("dataset_versions").
+		OrderBy("id DESC").
+		Limit(1)
+}
+
+// replicaLagQuery returns how far a replica's replay is behind, in seconds.
+// A replica that has replayed everything it received isn't lagging however
+// old its last transaction is, since the primary may just be idle. Run on
+// a primary, it returns 0.
+func replicaLagQuery() sq.SelectBuilder {
+	return psql.Select(`CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0) END::float8`)
+}
+
+// The snapshot queries read everything lookups can reach: every
+// jurisdiction, the newest active rate of each type, and active mappings.
+
+func snapshotJurisdictionsQuery() sq.SelectBuilder {
+	return psql.
+		Select(append(jurisdictionColumns(""), "boundary_type")...).
+		From("jurisdictions").
+		OrderBy("fips_code")
+}
+
+func snapshotRatesQuery() sq.SelectBuilder {
+	return psql.
+		Select("id", "fips_code", "rate", "rate_type", "effective_date", "expiry_date", "source").
+		Options("DISTINCT ON (fips_code, rate_type)").
+		From("rates").
+		Where("expiry_date IS NULL").
+		OrderBy("fips_code", "rate_type", "effective_date DESC")
+}
+
+func snapshotZIPsQuery() sq.SelectBuilder {
+	return psql.
+		Select("zip_code", "fips_code", "is_primary", "effective_date", "expiry_date").
+		From("zip_to_jurisdictions").
+		Where("expiry_date IS NULL").
+		OrderBy("zip_code", "is_primary DESC", "fips_code")
+}
+
+func snapshotZIP4sQuery() sq.SelectBuilder {
+	return psql.
+		Select("zip_code", "plus4_low", "plus4_high", "fips_code", "effective_date", "expiry_date").
+		From("zip4_to_jurisdictions").
+		Where("expiry_date IS NULL").
+		OrderBy("zip_code", "plus4_low", "fips_code")
+}
@@ -0,0 +1,245 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// replicaCooldown is how long a failed replica gets no lookups before it's
+// tried again.
+const replicaCooldown = 10 * time.Second
+
+// replica is one read replica's pool and health.
+type replica struct {
+	name string // host:port, safe to log
+	pool *pgxpool.Pool
+
+	// downUntil is when to try the replica again after a failure, in Unix
+	// nanoseconds; zero while it's healthy.
+	downUntil atomic.Int64
+	lastErr   atomic.Pointer[string]
+
+	// current is set while the replica has replayed the primary's latest
+	// dataset version within the lag limit, as of the last check.
+	current atomic.Bool
+	lag     atomic.Int64 // replay lag at the last check, in nanoseconds
+}
+
+func newReplica(ctx context.Context, url string) (*replica, error) {
+	cfg, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, fmt.Errorf("parsing replica URL: %w", err)
+	}
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to replica: %w", err)
+	}
+	return &replica{name: fmt.Sprintf("%s:%d", cfg.ConnConfig.Host, cfg.ConnConfig.Port), pool: pool}, nil
+}
+
+// serving reports whether the replica should get lookups.
+func (r *replica) serving(now time.Time) bool {
+	return r.current.Load() && r.up(now)
+}
+
+func (r *replica) up(now time.Time) bool {
+	until := r.downUntil.Load()
+	return until == 0 || now.UnixNano() >= until
+}
+
+// markDown takes the replica out of rotation for replicaCooldown.
+func (r *replica) markDown(err error) {
+	msg := err.Error()
+	r.lastErr.Store(&msg)
+	if r.downUntil.Swap(time.Now().Add(replicaCooldown).UnixNano()) == 0 {
+		slog.Warn("read replica failed, routing its lookups to primary", "replica", r.name, "error", err)
+	}
+}
+
+func (r *replica) markUp() {
+	if r.downUntil.Load() != 0 && r.downUntil.Swap(0) != 0 {
+		slog.Info("read replica recovered", "replica", r.name)
+	}
+}
+
+// setCurrent puts the replica in or out of rotation after a check.
+func (r *replica) setCurrent(current bool, version string) {
+	if r.current.Swap(current) == current {
+		return
+	}
+	lag := time.Duration(r.lag.Load())
+	if current {
+		slog.Info("read replica caught up", "replica", r.name, "version", version, "lag", lag)
+	} else {
+		slog.Warn("read replica behind primary, routing its lookups to primary", "replica", r.name, "version", version, "lag", lag)
+	}
+}
+
+// checkReplicas takes replicas that haven't replayed version, or lag the
+// primary by more than the limit, out of rotation, and puts back the ones
+// that have caught up.
+func (s *Postgres) checkReplicas(ctx context.Context, version string) {
+	for _, r := range s.replicas {
+		v, lag, err := r.check(ctx)
+		if err != nil {
+			if replicaFailed(ctx, err) {
+				r.markDown(err)
+			}
+			r.setCurrent(false, version)
+			continue
+		}
+		r.markUp()
+		r.lag.Store(int64(lag))
+		r.setCurrent(v == version && (s.maxLag <= 0 || lag <= s.maxLag), version)
+	}
+}
+
+// check returns the dataset version the replica has replayed and how far
+// its replay lags.
+func (r *replica) check(ctx context.Context) (string, time.Duration, error) {
+	version, err := datasetVersion(ctx, r.pool)
+	if err != nil {
+		return "", 0, err
+	}
+	query, args, err := replicaLagQuery().ToSql()
+	if err != nil {
+		return "", 0, fmt.Errorf("building query: %w", err)
+	}
+	var seconds float64
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&seconds); err != nil {
+		return "", 0, fmt.Errorf("querying replica lag: %w", err)
+	}
+	return version, time.Duration(seconds * float64(time.Second)), nil
+}
+
+// ReplicaStatus reports one read replica's health.
+type ReplicaStatus struct {
+	Name string `json:"name"`
+	Up   bool   `json:"up"`
+	// Current is false while the replica is behind the primary's dataset
+	// version or over the lag limit.
+	Current    bool    `json:"current"`
+	LagSeconds float64 `json:"lag_seconds"`
+	InUse      int32   `json:"in_use"` // connections running a query
+	LastError  string  `json:"last_error,omitempty"`
+}
+
+// Replicas returns the status of each read replica, in configuration order.
+func (s *Postgres) Replicas() []ReplicaStatus {
+	now := time.Now()
+	statuses := make([]ReplicaStatus, 0, len(s.replicas))
+	for _, r := range s.replicas {
+		st := ReplicaStatus{
+			Name:       r.name,
+			Up:         r.up(now),
+			Current:    r.current.Load(),
+			LagSeconds: time.Duration(r.lag.Load()).Seconds(),
+			InUse:      r.pool.Stat().AcquiredConns(),
+		}
+		if msg := r.lastErr.Load(); msg != nil && !st.Up {
+			st.LastError = *msg
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses
+}
+
+// pickReplica returns the healthy, current replica with the fewest
+// connections in use, rotating among ties, or nil when there's none.
+func (s *Postgres) pickReplica() *replica {
+	n := len(s.replicas)
+	if n == 0 {
+		return nil
+	}
+	now := time.Now()
+	start := int(s.next.Add(1) % uint64(n))
+
+	var best *replica
+	var bestInUse int32
+	for i := range n {
+		r := s.replicas[(start+i)%n]
+		if !r.serving(now) {
+			continue
+		}
+		if inUse := r.pool.Stat().AcquiredConns(); best == nil || inUse < bestInUse {
+			best, bestInUse = r, inUse
+		}
+	}
+	return best
+}
+
+// query runs a read-only query on a replica, or on the primary when no
+// replica is serving or the chosen one fails to run it. Failures after
+// rows start arriving are returned as they are.
+func (s *Postgres) query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if r := s.pickReplica(); r != nil {
+		rows, err := r.pool.Query(ctx, sql, args...)
+		if err == nil {
+			r.markUp()
+			return rows, nil
+		}
+		if !replicaFailed(ctx, err) {
+			return nil, err
+		}
+		r.markDown(err)
+	}
+	return s.primary.Query(ctx, sql, args...)
+}
+
+// queryRow is query for a single row.
+func (s *Postgres) queryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return &routedRow{s: s, ctx: ctx, sql: sql, args: args}
+}
+
+// routedRow defers choosing a pool until Scan, where pgx reports errors
+// for a single-row query.
+type routedRow struct {
+	s    *Postgres
+	ctx  context.Context
+	sql  string
+	args []any
+}
+
+func (row *routedRow) Scan(dest ...any) error {
+	if r := row.s.pickReplica(); r != nil {
+		err := r.pool.QueryRow(row.ctx, row.sql, row.args...).Scan(dest...)
+		if err == nil {
+			r.markUp()
+			return nil
+		}
+		if !replicaFailed(row.ctx, err) {
+			return err
+		}
+		r.markDown(err)
+	}
+	return row.s.primary.QueryRow(row.ctx, row.sql, row.args...).Scan(dest...)
+}
+
+// replicaFailed reports whether err means the replica couldn't be reached
+// rather than that the query itself failed. Only connection errors count:
+// a canceled query or statement timeout (57014) says nothing about the
+// replica, and a caller giving up isn't the replica's fault. Lag is tracked
+// separately.
+func replicaFailed(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// Class 08: connection exception.
+		return strings.HasPrefix(pgErr.Code, "08")
+	}
+	var connErr *pgconn.ConnectError
+	var netErr net.Error
+	return errors.As(err, &connErr) || errors.As(err, &netErr) || pgconn.SafeToRetry(err)
+}
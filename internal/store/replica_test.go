@@ -0,0 +1,84 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestReplicaFailed(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{"connection failure", context.Background(), &pgconn.PgError{Code: "08006"}, true},
+		{"wrapped connection failure", context.Background(), fmt.Errorf("querying: %w", &pgconn.PgError{Code: "08001"}), true},
+		{"statement timeout", context.Background(), &pgconn.PgError{Code: "57014"}, false},
+		{"recovery conflict", context.Background(), &pgconn.PgError{Code: "40001"}, false},
+		{"connect error", context.Background(), &pgconn.ConnectError{}, true},
+		{"unique violation", context.Background(), &pgconn.PgError{Code: "23505"}, false},
+		{"no rows", context.Background(), pgx.ErrNoRows, false},
+		{"caller canceled", canceled, &pgconn.PgError{Code: "08006"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := replicaFailed(tt.ctx, tt.err); got != tt.want {
+				t.Errorf("replicaFailed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPickReplica_SkipsDownReplicas(t *testing.T) {
+	ctx := context.Background()
+	s := &Postgres{}
+	for range 2 {
+		// Pools connect lazily, so nothing needs to listen here.
+		r, err := newReplica(ctx, "postgres://localhost:1/salestax")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(r.pool.Close)
+		r.current.Store(true)
+		s.replicas = append(s.replicas, r)
+	}
+
+	s.replicas[0].markDown(errors.New("connection refused"))
+	for range 4 {
+		if r := s.pickReplica(); r != s.replicas[1] {
+			t.Fatal("pickReplica chose a replica that's down")
+		}
+	}
+
+	s.replicas[1].markDown(errors.New("connection refused"))
+	if r := s.pickReplica(); r != nil {
+		t.Error("pickReplica chose a replica with all of them down")
+	}
+	st := s.Replicas()
+	if st[0].Up || st[0].LastError != "connection refused" {
+		t.Errorf("Replicas()[0] = %+v, want down with its error", st[0])
+	}
+
+	s.replicas[0].markUp()
+	if r := s.pickReplica(); r != s.replicas[0] {
+		t.Error("pickReplica skipped a recovered replica")
+	}
+
+	// A replica behind the promoted version gets no lookups, reachable or not.
+	s.replicas[0].setCurrent(false, "v2")
+	if r := s.pickReplica(); r != nil {
+		t.Error("pickReplica chose a replica that's behind")
+	}
+	if st := s.Replicas(); !st[0].Up || st[0].Current {
+		t.Errorf("Replicas()[0] = %+v, want up but not current", st[0])
+	}
+}
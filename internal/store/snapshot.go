@@ -0,0 +1,293 @@
+package store
+
+import (
+	"cmp"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"time"
+)
+
+// SnapshotFormat is the version of the snapshot file layout. ReadSnapshot
+// rejects files written in any other format.
+const SnapshotFormat = 1
+
+// Snapshot is a point-in-time copy of the data lookups read: every
+// jurisdiction, the newest active rate of each type, and the active ZIP
+// and ZIP+4 mappings. Files are gzipped JSON.
+type Snapshot struct {
+	Format         int                    `json:"format"`
+	DatasetVersion string                 `json:"dataset_version"`
+	ExportedAt     time.Time              `json:"exported_at"`
+	LastUpdated    time.Time              `json:"last_updated"`
+	Jurisdictions  []SnapshotJurisdiction `json:"jurisdictions"`
+	Rates          []Rate                 `json:"rates"`
+	ZIPs           []ZIPJurisdiction      `json:"zips"`
+	ZIP4s          []ZIP4Jurisdiction     `json:"zip4s"`
+}
+
+// SnapshotJurisdiction is a jurisdiction with how its extent is known,
+// which decides whether it's attached as a parent fallback.
+type SnapshotJurisdiction struct {
+	Jurisdiction
+	BoundaryType string `json:"boundary_type"`
+}
+
+// WriteSnapshot writes snap to w, gzipped.
+func WriteSnapshot(w io.Writer, snap *Snapshot) error {
+	zw := gzip.NewWriter(w)
+	if err := json.NewEncoder(zw).Encode(snap); err != nil {
+		return fmt.Errorf("encoding snapshot: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("compressing snapshot: %w", err)
+	}
+	return nil
+}
+
+// ReadSnapshot reads a snapshot written by WriteSnapshot.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing snapshot: %w", err)
+	}
+	defer zr.Close()
+
+	var snap Snapshot
+	if err := json.NewDecoder(zr).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("decoding snapshot: %w", err)
+	}
+	if snap.Format != SnapshotFormat {
+		return nil, fmt.Errorf("snapshot format %d, want %d", snap.Format, SnapshotFormat)
+	}
+	return &snap, nil
+}
+
+// SnapshotStore is a read-only Store serving a snapshot from memory.
+type SnapshotStore struct {
+	version       string
+	lastUpdated   time.Time
+	jurisdictions map[string]Jurisdiction
+	zips          map[string][]ZIPMatch // primary first
+	activeZIPs    []string              // sorted
+	zip4s         map[string][]ZIP4Jurisdiction
+	rates         map[string][]Rate                 // by rate type
+	districts     map[string][]SnapshotJurisdiction // special districts by parent
+}
+
+// LoadSnapshot reads the snapshot file at path into a SnapshotStore.
+func LoadSnapshot(path string) (*SnapshotStore, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening snapshot: %w", err)
+	}
+	defer f.Close()
+
+	snap, err := ReadSnapshot(f)
+	if err != nil {
+		return nil, err
+	}
+	return NewSnapshotStore(snap)
+}
+
+// NewSnapshotStore indexes snap for lookups. It fails if a mapping or rate
+// refers to a jurisdiction the snapshot doesn't have.
+func NewSnapshotStore(snap *Snapshot) (*SnapshotStore, error) {
+	s := &SnapshotStore{
+		version:       snap.DatasetVersion,
+		lastUpdated:   snap.LastUpdated,
+		jurisdictions: make(map[string]Jurisdiction, len(snap.Jurisdictions)),
+		zips:          make(map[string][]ZIPMatch),
+		zip4s:         make(map[string][]ZIP4Jurisdiction),
+		rates:         make(map[string][]Rate),
+		districts:     make(map[string][]SnapshotJurisdiction),
+	}
+	if s.version == "" {
+		s.version = DatasetVersionNone
+	}
+
+	for _, sj := range snap.Jurisdictions {
+		j := sj.Jurisdiction
+		s.jurisdictions[j.FIPSCode] = j
+		if j.Type == "special_district" && j.ParentFIPS != nil {
+			s.districts[*j.ParentFIPS] = append(s.districts[*j.ParentFIPS], sj)
+		}
+	}
+
+	for _, z := range snap.ZIPs {
+		if z.ExpiryDate != nil {
+			continue
+		}
+		j, ok := s.jurisdictions[z.FIPSCode]
+		if !ok {
+			return nil, fmt.Errorf("zip %s maps to unknown jurisdiction %s", z.ZIPCode, z.FIPSCode)
+		}
+		if _, seen := s.zips[z.ZIPCode]; !seen {
+			s.activeZIPs = append(s.activeZIPs, z.ZIPCode)
+		}
+		s.zips[z.ZIPCode] = append(s.zips[z.ZIPCode], ZIPMatch{Jurisdiction: j, IsPrimary: z.IsPrimary})
+	}
+	sort.Strings(s.activeZIPs)
+	for _, matches := range s.zips {
+		slices.SortStableFunc(matches, func(a, b ZIPMatch) int {
+			switch {
+			case a.IsPrimary == b.IsPrimary:
+				return 0
+			case a.IsPrimary:
+				return -1
+			default:
+				return 1
+			}
+		})
+	}
+
+	for _, z := range snap.ZIP4s {
+		if z.ExpiryDate != nil {
+			continue
+		}
+		if _, ok := s.jurisdictions[z.FIPSCode]; !ok {
+			return nil, fmt.Errorf("zip+4 range %s-%s..%s maps to unknown jurisdiction %s", z.ZIPCode, z.Plus4Low, z.Plus4High, z.FIPSCode)
+		}
+		s.zip4s[z.ZIPCode] = append(s.zip4s[z.ZIPCode], z)
+	}
+
+	// Keep the newest active rate of each type, as the queries do.
+	newest := make(map[[2]string]Rate)
+	for _, r := range snap.Rates {
+		if r.ExpiryDate != nil {
+			continue
+		}
+		if _, ok := s.jurisdictions[r.FIPSCode]; !ok {
+			return nil, fmt.Errorf("rate %d refers to unknown jurisdiction %s", r.ID, r.FIPSCode)
+		}
+		key := [2]string{r.FIPSCode, r.RateType}
+		if cur, ok := newest[key]; !ok || r.EffectiveDate.After(cur.EffectiveDate) {
+			newest[key] = r
+		}
+	}
+	for _, r := range newest {
+		s.rates[r.FIPSCode] = append(s.rates[r.FIPSCode], r)
+	}
+	for _, rates := range s.rates {
+		slices.SortFunc(rates, func(a, b Rate) int { return cmp.Compare(a.RateType, b.RateType) })
+	}
+
+	return s, nil
+}
+
+func (s *SnapshotStore) Ping(context.Context) error { return nil }
+
+func (s *SnapshotStore) Close() {}
+
+func (s *SnapshotStore) GetZIPMatches(_ context.Context, zip string) ([]ZIPMatch, error) {
+	return slices.Clone(s.zips[zip]), nil
+}
+
+func (s *SnapshotStore) GetZIPMatchesForZIPs(_ context.Context, zips []string) (map[string][]ZIPMatch, error) {
+	matches := make(map[string][]ZIPMatch, len(zips))
+	for _, zip := range zips {
+		if m, ok := s.zips[zip]; ok {
+			matches[zip] = slices.Clone(m)
+		}
+	}
+	return matches, nil
+}
+
+func (s *SnapshotStore) ListActiveZIPs(_ context.Context, after string, limit int) ([]string, error) {
+	i := sort.SearchStrings(s.activeZIPs, after)
+	if i < len(s.activeZIPs) && s.activeZIPs[i] == after {
+		i++
+	}
+	end := min(i+limit, len(s.activeZIPs))
+	return slices.Clone(s.activeZIPs[i:end]), nil
+}
+
+func (s *SnapshotStore) CountActiveZIPs(context.Context) (int, error) {
+	return len(s.activeZIPs), nil
+}
+
+func (s *SnapshotStore) GetJurisdictionsByZIP4(_ context.Context, zip, plus4 string) ([]Jurisdiction, error) {
+	var jurisdictions []Jurisdiction
+	for _, r := range s.zip4s[zip] {
+		if r.Plus4Low <= plus4 && plus4 <= r.Plus4High {
+			jurisdictions = append(jurisdictions, s.jurisdictions[r.FIPSCode])
+		}
+	}
+	sortByType(jurisdictions)
+	return jurisdictions, nil
+}
+
+func (s *SnapshotStore) GetJurisdictionsByFIPSCodes(_ context.Context, fipsCodes []string) ([]Jurisdiction, error) {
+	var jurisdictions []Jurisdiction
+	seen := make(map[string]bool, len(fipsCodes))
+	for _, code := range fipsCodes {
+		if j, ok := s.jurisdictions[code]; ok && !seen[code] {
+			seen[code] = true
+			jurisdictions = append(jurisdictions, j)
+		}
+	}
+	sortByType(jurisdictions)
+	return jurisdictions, nil
+}
+
+func (s *SnapshotStore) GetFallbackDistricts(_ context.Context, parentFIPS, boundaryTypes []string) ([]Jurisdiction, error) {
+	var districts []Jurisdiction
+	seen := make(map[string]bool, len(parentFIPS))
+	for _, parent := range parentFIPS {
+		if seen[parent] {
+			continue
+		}
+		seen[parent] = true
+		for _, sj := range s.districts[parent] {
+			if slices.Contains(boundaryTypes, sj.BoundaryType) {
+				districts = append(districts, sj.Jurisdiction)
+			}
+		}
+	}
+	slices.SortFunc(districts, func(a, b Jurisdiction) int { return cmp.Compare(a.FIPSCode, b.FIPSCode) })
+	for i := range districts {
+		districts[i].ParentFallback = true
+	}
+	return districts, nil
+}
+
+func (s *SnapshotStore) GetRateByFIPS(_ context.Context, fipsCode string) (*Rate, error) {
+	for _, r := range s.rates[fipsCode] {
+		if r.RateType == RateGeneral {
+			return &r, nil
+		}
+	}
+	return nil, fmt.Errorf("no general rate for %s", fipsCode)
+}
+
+func (s *SnapshotStore) GetRatesByFIPS(_ context.Context, fipsCode string) ([]Rate, error) {
+	return slices.Clone(s.rates[fipsCode]), nil
+}
+
+func (s *SnapshotStore) GetActiveRatesByFIPSCodes(_ context.Context, fipsCodes []string) (map[string][]Rate, error) {
+	rates := make(map[string][]Rate, len(fipsCodes))
+	for _, code := range fipsCodes {
+		if r, ok := s.rates[code]; ok {
+			rates[code] = slices.Clone(r)
+		}
+	}
+	return rates, nil
+}
+
+func (s *SnapshotStore) GetDataFreshness(context.Context) (*DataFreshness, error) {
+	return &DataFreshness{LastUpdated: s.lastUpdated, RecordCount: len(s.jurisdictions)}, nil
+}
+
+func (s *SnapshotStore) GetDatasetVersion(context.Context) (string, error) {
+	return s.version, nil
+}
+
+// sortByType orders jurisdictions by type, as the queries do.
+func sortByType(jurisdictions []Jurisdiction) {
+	slices.SortStableFunc(jurisdictions, func(a, b Jurisdiction) int { return cmp.Compare(a.Type, b.Type) })
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testSnapshot() *Snapshot {
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	state, county := "06", "06037"
+	expired := day.AddDate(0, -1, 0)
+	return &Snapshot{
+		Format:         SnapshotFormat,
+		DatasetVersion: "v1",
+		LastUpdated:    day,
+		Jurisdictions: []SnapshotJurisdiction{
+			{Jurisdiction: Jurisdiction{FIPSCode: "06", Name: "California", Type: "state", StateFIPS: "06"}, BoundaryType: "parent"},
+			{Jurisdiction: Jurisdiction{FIPSCode: "06037", Name: "Los Angeles", Type: "county", StateFIPS: "06", ParentFIPS: &state}, BoundaryType: "parent"},
+			{Jurisdiction: Jurisdiction{FIPSCode: "0644000", Name: "Los Angeles", Type: "city", StateFIPS: "06", ParentFIPS: &county}, BoundaryType: "parent"},
+			{Jurisdiction: Jurisdiction{FIPSCode: "06037D1", Name: "Transit", Type: "special_district", StateFIPS: "06", ParentFIPS: &county}, BoundaryType: "parent"},
+			{Jurisdiction: Jurisdiction{FIPSCode: "06037D2", Name: "Library", Type: "special_district", StateFIPS: "06", ParentFIPS: &county}, BoundaryType: "zip4"},
+		},
+		Rates: []Rate{
+			{ID: 1, FIPSCode: "06", Rate: 0.06, RateType: RateGeneral, EffectiveDate: day.AddDate(-1, 0, 0)},
+			{ID: 2, FIPSCode: "06", Rate: 0.0725, RateType: RateGeneral, EffectiveDate: day},
+			{ID: 3, FIPSCode: "06", Rate: 0.05, RateType: RateLodging, EffectiveDate: day},
+			{ID: 4, FIPSCode: "06037", Rate: 0.01, RateType: RateGeneral, EffectiveDate: day, ExpiryDate: &expired},
+		},
+		ZIPs: []ZIPJurisdiction{
+			{ZIPCode: "90012", FIPSCode: "06037", IsPrimary: false, EffectiveDate: day},
+			{ZIPCode: "90012", FIPSCode: "06", IsPrimary: true, EffectiveDate: day},
+			{ZIPCode: "90001", FIPSCode: "06", IsPrimary: true, EffectiveDate: day},
+			{ZIPCode: "90002", FIPSCode: "06", IsPrimary: true, EffectiveDate: day, ExpiryDate: &expired},
+		},
+		ZIP4s: []ZIP4Jurisdiction{
+			{ZIPCode: "90012", Plus4Low: "1000", Plus4High: "1999", FIPSCode: "0644000", EffectiveDate: day},
+			{ZIPCode: "90012", Plus4Low: "1000", Plus4High: "1999", FIPSCode: "06", EffectiveDate: day},
+		},
+	}
+}
+
+func TestSnapshotStore_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, testSnapshot()); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSnapshotStore(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if v, _ := s.GetDatasetVersion(ctx); v != "v1" {
+		t.Errorf("version = %q, want v1", v)
+	}
+
+	matches, _ := s.GetZIPMatches(ctx, "90012")
+	if len(matches) != 2 || !matches[0].IsPrimary || matches[0].FIPSCode != "06" {
+		t.Errorf("GetZIPMatches(90012) = %+v, want the primary state match first", matches)
+	}
+
+	zips, _ := s.ListActiveZIPs(ctx, "", 10)
+	if strings.Join(zips, ",") != "90001,90012" {
+		t.Errorf("ListActiveZIPs = %v, want the unexpired ZIPs in order", zips)
+	}
+	if zips, _ := s.ListActiveZIPs(ctx, "90001", 10); strings.Join(zips, ",") != "90012" {
+		t.Errorf("ListActiveZIPs after 90001 = %v", zips)
+	}
+
+	ranged, _ := s.GetJurisdictionsByZIP4(ctx, "90012", "1234")
+	if len(ranged) != 2 || ranged[0].Type != "city" || ranged[1].Type != "state" {
+		t.Errorf("GetJurisdictionsByZIP4 = %+v, want city and state ordered by type", ranged)
+	}
+	if ranged, _ := s.GetJurisdictionsByZIP4(ctx, "90012", "2000"); len(ranged) != 0 {
+		t.Errorf("GetJurisdictionsByZIP4 outside range = %+v, want none", ranged)
+	}
+
+	rates, _ := s.GetRatesByFIPS(ctx, "06")
+	if len(rates) != 2 || rates[0].RateType != RateGeneral || rates[0].Rate != 0.0725 {
+		t.Errorf("GetRatesByFIPS(06) = %+v, want the newest general rate and lodging", rates)
+	}
+	if _, err := s.GetRateByFIPS(ctx, "06037"); err == nil {
+		t.Error("GetRateByFIPS(06037) succeeded, want an error for an expired-only rate")
+	}
+
+	districts, _ := s.GetFallbackDistricts(ctx, []string{"06037", "06037"}, []string{BoundaryParent})
+	if len(districts) != 1 || districts[0].FIPSCode != "06037D1" || !districts[0].ParentFallback {
+		t.Errorf("GetFallbackDistricts = %+v, want only the parent-bounded district", districts)
+	}
+	districts, _ = s.GetFallbackDistricts(ctx, []string{"06037"}, []string{BoundaryParent, BoundaryZIP4})
+	if len(districts) != 2 {
+		t.Errorf("GetFallbackDistricts with zip4 = %+v, want both districts", districts)
+	}
+}
+
+func TestNewSnapshotStore_UnknownJurisdiction(t *testing.T) {
+	snap := testSnapshot()
+	snap.ZIPs = append(snap.ZIPs, ZIPJurisdiction{ZIPCode: "99999", FIPSCode: "99"})
+	if _, err := NewSnapshotStore(snap); err == nil {
+		t.Error("expected an error for a mapping to an unknown jurisdiction")
+	}
+}
+
+func TestReadSnapshot_RejectsOtherFormat(t *testing.T) {
+	snap := testSnapshot()
+	snap.Format = SnapshotFormat + 1
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, snap); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadSnapshot(&buf); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,26 @@
+package store
+
+import "context"
+
+// Store reads jurisdictions, rates and ZIP mappings. Postgres reads the
+// live database; SnapshotStore serves a read-only copy loaded from a
+// snapshot file, for deployments without Postgres.
+type Store interface {
+	Ping(ctx context.Context) error
+	Close()
+
+	GetZIPMatches(ctx context.Context, zip string) ([]ZIPMatch, error)
+	GetZIPMatchesForZIPs(ctx context.Context, zips []string) (map[string][]ZIPMatch, error)
+	ListActiveZIPs(ctx context.Context, after string, limit int) ([]string, error)
+	CountActiveZIPs(ctx context.Context) (int, error)
+	GetJurisdictionsByZIP4(ctx context.Context, zip, plus4 string) ([]Jurisdiction, error)
+	GetJurisdictionsByFIPSCodes(ctx context.Context, fipsCodes []string) ([]Jurisdiction, error)
+	GetFallbackDistricts(ctx context.Context, parentFIPS, boundaryTypes []string) ([]Jurisdiction, error)
+
+	GetRateByFIPS(ctx context.Context, fipsCode string) (*Rate, error)
+	GetRatesByFIPS(ctx context.Context, fipsCode string) ([]Rate, error)
+	GetActiveRatesByFIPSCodes(ctx context.Context, fipsCodes []string) (map[string][]Rate, error)
+
+	GetDataFreshness(ctx context.Context) (*DataFreshness, error)
+	GetDatasetVersion(ctx context.Context) (string, error)
+}
@@ -0,0 +1,57 @@
+package storetest
+
+import (
+	"time"
+
+	"github.com/prashkn/sales-tax-api/internal/store"
+)
+
+// Sample returns the California part of the seed migration: ZIPs 90210
+// (Beverly Hills, 9.25%) and 90001 (Los Angeles, 8.00%), both in LA
+// County with the LA Metro district attached by parent. The state also
+// levies a 12% lodging rate, for rate type tests.
+func Sample() *store.Snapshot {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	state, county := "06", "06037"
+
+	jurisdiction := func(fips, name, typ string, parent *string) store.SnapshotJurisdiction {
+		return store.SnapshotJurisdiction{
+			Jurisdiction: store.Jurisdiction{
+				FIPSCode: fips, Name: name, Type: typ, StateFIPS: "06", ParentFIPS: parent,
+				EffectiveDate: day, Administration: store.AdminStateAdministered,
+			},
+			BoundaryType: "parent",
+		}
+	}
+	rate := func(id int, fips string, r float64, rateType string) store.Rate {
+		return store.Rate{ID: id, FIPSCode: fips, Rate: r, RateType: rateType, EffectiveDate: day, Source: "state_gov"}
+	}
+	zip := func(code, fips string) store.ZIPJurisdiction {
+		return store.ZIPJurisdiction{ZIPCode: code, FIPSCode: fips, IsPrimary: true, EffectiveDate: day}
+	}
+
+	return &store.Snapshot{
+		Format:         store.SnapshotFormat,
+		DatasetVersion: "sample",
+		LastUpdated:    day,
+		Jurisdictions: []store.SnapshotJurisdiction{
+			jurisdiction("06", "California", "state", nil),
+			jurisdiction("06037", "Los Angeles County", "county", &state),
+			jurisdiction("0603744000", "Beverly Hills", "city", &county),
+			jurisdiction("0644000", "Los Angeles", "city", &county),
+			jurisdiction("06037SD01", "LA Metro Transportation Authority", "special_district", &county),
+		},
+		Rates: []store.Rate{
+			rate(1, "06", 0.0725, store.RateGeneral),
+			rate(2, "06", 0.12, store.RateLodging),
+			rate(3, "06037", 0.0025, store.RateGeneral),
+			rate(4, "0603744000", 0.0125, store.RateGeneral),
+			rate(5, "0644000", 0, store.RateGeneral),
+			rate(6, "06037SD01", 0.005, store.RateGeneral),
+		},
+		ZIPs: []store.ZIPJurisdiction{
+			zip("90210", "06"), zip("90210", "06037"), zip("90210", "0603744000"), zip("90210", "06037SD01"),
+			zip("90001", "06"), zip("90001", "06037"), zip("90001", "0644000"), zip("90001", "06037SD01"),
+		},
+	}
+}
@@ -0,0 +1,145 @@
+// Package storetest provides an in-memory store.Store for tests, seeded
+// from a store.Snapshot, that can be made to fail like an unreachable
+// database.
+package storetest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/prashkn/sales-tax-api/internal/store"
+)
+
+// Fake is a store.Store serving snap from memory. Every call, including
+// Ping, fails with the error set by SetErr until it's cleared.
+type Fake struct {
+	data *store.SnapshotStore
+
+	mu    sync.Mutex
+	err   error
+	calls int
+}
+
+// New returns a Fake seeded with snap, failing t if snap is inconsistent.
+func New(t testing.TB, snap *store.Snapshot) *Fake {
+	t.Helper()
+	data, err := store.NewSnapshotStore(snap)
+	if err != nil {
+		t.Fatalf("seeding fake store: %v", err)
+	}
+	return &Fake{data: data}
+}
+
+// SetErr makes every call fail with err; nil restores normal operation.
+func (f *Fake) SetErr(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+// Calls returns how many calls have been made, not counting Ping.
+func (f *Fake) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func (f *Fake) call() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	return f.err
+}
+
+func (f *Fake) Ping(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.err
+}
+
+func (f *Fake) Close() {}
+
+func (f *Fake) GetZIPMatches(ctx context.Context, zip string) ([]store.ZIPMatch, error) {
+	if err := f.call(); err != nil {
+		return nil, err
+	}
+	return f.data.GetZIPMatches(ctx, zip)
+}
+
+func (f *Fake) GetZIPMatchesForZIPs(ctx context.Context, zips []string) (map[string][]store.ZIPMatch, error) {
+	if err := f.call(); err != nil {
+		return nil, err
+	}
+	return f.data.GetZIPMatchesForZIPs(ctx, zips)
+}
+
+func (f *Fake) ListActiveZIPs(ctx context.Context, after string, limit int) ([]string, error) {
+	if err := f.call(); err != nil {
+		return nil, err
+	}
+	return f.data.ListActiveZIPs(ctx, after, limit)
+}
+
+func (f *Fake) CountActiveZIPs(ctx context.Context) (int, error) {
+	if err := f.call(); err != nil {
+		return 0, err
+	}
+	return f.data.CountActiveZIPs(ctx)
+}
+
+func (f *Fake) GetJurisdictionsByZIP4(ctx context.Context, zip, plus4 string) ([]store.Jurisdiction, error) {
+	if err := f.call(); err != nil {
+		return nil, err
+	}
+	return f.data.GetJurisdictionsByZIP4(ctx, zip, plus4)
+}
+
+func (f *Fake) GetJurisdictionsByFIPSCodes(ctx context.Context, fipsCodes []string) ([]store.Jurisdiction, error) {
+	if err := f.call(); err != nil {
+		return nil, err
+	}
+	return f.data.GetJurisdictionsByFIPSCodes(ctx, fipsCodes)
+}
+
+func (f *Fake) GetFallbackDistricts(ctx context.Context, parentFIPS, boundaryTypes []string) ([]store.Jurisdiction, error) {
+	if err := f.call(); err != nil {
+		return nil, err
+	}
+	return f.data.GetFallbackDistricts(ctx, parentFIPS, boundaryTypes)
+}
+
+func (f *Fake) GetRateByFIPS(ctx context.Context, fipsCode string) (*store.Rate, error) {
+	if err := f.call(); err != nil {
+		return nil, err
+	}
+	return f.data.GetRateByFIPS(ctx, fipsCode)
+}
+
+func (f *Fake) GetRatesByFIPS(ctx context.Context, fipsCode string) ([]store.Rate, error) {
+	if err := f.call(); err != nil {
+		return nil, err
+	}
+	return f.data.GetRatesByFIPS(ctx, fipsCode)
+}
+
+func (f *Fake) GetActiveRatesByFIPSCodes(ctx context.Context, fipsCodes []string) (map[string][]store.Rate, error) {
+	if err := f.call(); err != nil {
+		return nil, err
+	}
+	return f.data.GetActiveRatesByFIPSCodes(ctx, fipsCodes)
+}
+
+func (f *Fake) GetDataFreshness(ctx context.Context) (*store.DataFreshness, error) {
+	if err := f.call(); err != nil {
+		return nil, err
+	}
+	return f.data.GetDataFreshness(ctx)
+}
+
+func (f *Fake) GetDatasetVersion(ctx context.Context) (string, error) {
+	if err := f.call(); err != nil {
+		return "", err
+	}
+	return f.data.GetDatasetVersion(ctx)
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS zip4_to_jurisdictions_staging;
+DROP TABLE IF EXISTS zip4_to_jurisdictions;
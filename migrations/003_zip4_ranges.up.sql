@@ -0,0 +1,29 @@
+-- ZIP+4 range mappings
+--
+-- Many 5-digit ZIPs straddle city or district boundaries. The SST boundary
+-- files publish 9-digit ranges; each row maps an inclusive plus-4 range within
+-- a ZIP to one jurisdiction, so a range's full jurisdiction set is every row
+-- sharing (zip_code, plus4_low, plus4_high).
+
+CREATE TABLE zip4_to_jurisdictions (
+    zip_code        TEXT NOT NULL,
+    plus4_low       TEXT NOT NULL CHECK (plus4_low ~ '^[0-9]{4}$'),
+    plus4_high      TEXT NOT NULL CHECK (plus4_high ~ '^[0-9]{4}$'),
+    fips_code       TEXT NOT NULL REFERENCES jurisdictions(fips_code),
+    effective_date  DATE NOT NULL,
+    expiry_date     DATE,
+    PRIMARY KEY (zip_code, plus4_low, plus4_high, fips_code, effective_date),
+    CHECK (plus4_low <= plus4_high)
+);
+
+CREATE TABLE zip4_to_jurisdictions_staging (
+    zip_code        TEXT NOT NULL,
+    plus4_low       TEXT NOT NULL,
+    plus4_high      TEXT NOT NULL,
+    fips_code       TEXT NOT NULL,
+    effective_date  DATE NOT NULL,
+    expiry_date     DATE,
+    PRIMARY KEY (zip_code, plus4_low, plus4_high, fips_code, effective_date)
+);
+
+CREATE INDEX idx_zip4_jurisdictions_range ON zip4_to_jurisdictions(zip_code, plus4_low, plus4_high) WHERE expiry_date IS NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_jurisdictions_parent_districts;
+ALTER TABLE jurisdictions DROP COLUMN IF EXISTS boundary_type;
@@ -0,0 +1,15 @@
+-- Special-district boundaries
+--
+-- Special districts rarely cover their whole parent county. boundary_type
+-- records how a district's extent is known so lookups can match it by
+-- location instead of attaching it to every address in the county:
+--   parent  no boundary on file; attached by parent_fips as a fallback
+--   zip4    covered by zip4_to_jurisdictions ranges
+--   polygon covered by a polygon in the BOUNDARY_FILES index
+
+ALTER TABLE jurisdictions
+    ADD COLUMN boundary_type TEXT NOT NULL DEFAULT 'parent'
+    CHECK (boundary_type IN ('parent', 'zip4', 'polygon'));
+
+CREATE INDEX idx_jurisdictions_parent_districts ON jurisdictions(parent_fips)
+    WHERE type = 'special_district' AND boundary_type = 'parent';
@@ -0,0 +1,9 @@
+-- Fails if any rows use the new types; remove them first.
+
+ALTER TABLE jurisdictions_staging DROP CONSTRAINT jurisdictions_staging_type_check;
+ALTER TABLE jurisdictions_staging ADD CONSTRAINT jurisdictions_staging_type_check
+    CHECK (type IN ('state', 'county', 'city', 'special_district'));
+
+ALTER TABLE jurisdictions DROP CONSTRAINT jurisdictions_type_check;
+ALTER TABLE jurisdictions ADD CONSTRAINT jurisdictions_type_check
+    CHECK (type IN ('state', 'county', 'city', 'special_district'));
@@ -0,0 +1,19 @@
+-- Additional jurisdiction types
+--
+-- Not every taxing body is a state, county, city or special district:
+--   county_subdivision  Census county subdivisions (New England towns, MCDs)
+--   borough             Alaska boroughs and other county-equivalent boroughs
+--   township            civil townships that levy their own tax
+--   tribal_area         tribal reservations and trust lands
+-- Louisiana parishes stay 'county' and Colorado home-rule cities stay
+-- 'city'; those differ in who administers the tax, not in where they sit.
+
+ALTER TABLE jurisdictions DROP CONSTRAINT jurisdictions_type_check;
+ALTER TABLE jurisdictions ADD CONSTRAINT jurisdictions_type_check
+    CHECK (type IN ('state', 'county', 'city', 'special_district',
+                    'county_subdivision', 'borough', 'township', 'tribal_area'));
+
+ALTER TABLE jurisdictions_staging DROP CONSTRAINT jurisdictions_staging_type_check;
+ALTER TABLE jurisdictions_staging ADD CONSTRAINT jurisdictions_staging_type_check
+    CHECK (type IN ('state', 'county', 'city', 'special_district',
+                    'county_subdivision', 'borough', 'township', 'tribal_area'));
@@ -0,0 +1,5 @@
+ALTER TABLE jurisdictions
+    DROP COLUMN IF EXISTS registration_id,
+    DROP COLUMN IF EXISTS registration_url,
+    DROP COLUMN IF EXISTS filing_authority,
+    DROP COLUMN IF EXISTS administration;
@@ -0,0 +1,14 @@
+-- Jurisdiction administration metadata
+--
+-- Most local sales taxes are collected by the state alongside its own, but
+-- some localities (Colorado home-rule cities, many Alabama and Louisiana
+-- localities) collect their own and require a separate registration.
+-- These columns are curated, not sourced from rate files, so the pipeline's
+-- jurisdiction upsert leaves them alone.
+
+ALTER TABLE jurisdictions
+    ADD COLUMN administration TEXT NOT NULL DEFAULT 'state_administered'
+        CHECK (administration IN ('state_administered', 'self_collected')),
+    ADD COLUMN filing_authority TEXT,
+    ADD COLUMN registration_url TEXT,
+    ADD COLUMN registration_id  TEXT;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_rates_fips_type_active;
+
+ALTER TABLE rate_history DROP COLUMN IF EXISTS rate_type;
+
+ALTER TABLE rates_staging DROP CONSTRAINT IF EXISTS rates_staging_rate_type_check;
+ALTER TABLE rates DROP CONSTRAINT IF EXISTS rates_rate_type_check;
@@ -0,0 +1,21 @@
+-- Non-general rate types
+--
+-- rates.rate_type has always existed, but only 'general' was loaded. A
+-- jurisdiction's rate of another type is the whole rate it levies on that
+-- kind of transaction, replacing its general rate:
+--   lodging        hotel and short-term rental stays
+--   rental_car     motor vehicle rentals
+--   prepared_food  restaurant meals and prepared food
+--   use            use tax on goods bought untaxed out of state
+-- Jurisdictions with no rate of a type are taxed at their general rate.
+
+ALTER TABLE rates ADD CONSTRAINT rates_rate_type_check
+    CHECK (rate_type IN ('general', 'lodging', 'rental_car', 'prepared_food', 'use'));
+
+ALTER TABLE rates_staging ADD CONSTRAINT rates_staging_rate_type_check
+    CHECK (rate_type IN ('general', 'lodging', 'rental_car', 'prepared_food', 'use'));
+
+ALTER TABLE rate_history ADD COLUMN rate_type TEXT NOT NULL DEFAULT 'general';
+
+CREATE INDEX idx_rates_fips_type_active ON rates(fips_code, rate_type, effective_date DESC)
+    WHERE expiry_date IS NULL;
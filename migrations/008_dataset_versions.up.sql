@@ -0,0 +1,12 @@
+-- Dataset versions
+--
+-- Each promotion records a version. Servers poll for the latest one and
+-- namespace their cached responses by it, so a promotion takes effect as
+-- soon as it's seen instead of when cached entries expire.
+
+CREATE TABLE dataset_versions (
+    id              SERIAL PRIMARY KEY,
+    version         TEXT NOT NULL UNIQUE,
+    pipeline_run_id TEXT NOT NULL,
+    promoted_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
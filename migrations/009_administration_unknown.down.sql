@@ -0,0 +1,9 @@
+UPDATE jurisdictions
+SET administration = 'state_administered'
+WHERE administration = 'unknown';
+
+ALTER TABLE jurisdictions
+    DROP CONSTRAINT jurisdictions_administration_check,
+    ADD CONSTRAINT jurisdictions_administration_check
+        CHECK (administration IN ('state_administered', 'self_collected')),
+    ALTER COLUMN administration SET DEFAULT 'state_administered';
@@ -0,0 +1,20 @@
+-- Unknown administration
+--
+-- Jurisdictions used to default to state_administered, so every locality
+-- nobody had curated was reported as collected by the state, including
+-- home-rule localities that collect their own tax. Uncurated localities are
+-- now 'unknown'. States always collect their own tax, so lookups report them
+-- as state_administered regardless. Curated rows are restored from the
+-- reference file on the next promote.
+
+ALTER TABLE jurisdictions
+    DROP CONSTRAINT jurisdictions_administration_check,
+    ADD CONSTRAINT jurisdictions_administration_check
+        CHECK (administration IN ('state_administered', 'self_collected', 'unknown')),
+    ALTER COLUMN administration SET DEFAULT 'unknown';
+
+UPDATE jurisdictions
+SET administration = 'unknown'
+WHERE administration = 'state_administered'
+    AND type <> 'state'
+    AND filing_authority IS NULL;
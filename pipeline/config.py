@@ -9,6 +9,10 @@ from datetime import date
 BASE_DIR = Path(__file__).resolve().parent
 RAW_DIR = BASE_DIR / "raw"
 REPORTS_DIR = BASE_DIR / "reports"
+REFERENCE_DIR = BASE_DIR / "reference"
+
+# Curated administration metadata for self-collected jurisdictions.
+ADMINISTRATION_FILE = REFERENCE_DIR / "jurisdiction_administration.csv"
 
 # --- Database ---
 
@@ -48,6 +52,12 @@ MAX_RATE = 0.15          # 15% ceiling — anything above is flagged
 MAX_RATE_DELTA = 0.02    # flag quarter-over-quarter changes > 2 pp
 MIN_ZIP_COVERAGE = 40000 # US has ~41k 5-digit ZIPs; flag if we cover fewer
 
+# --- Rate types ---
+
+# rates.rate_type values. A jurisdiction's rate of a non-general type replaces
+# its general rate for that kind of transaction.
+RATE_TYPES = ["general", "lodging", "rental_car", "prepared_food", "use"]
+
 # --- Source priority (higher number wins when merging) ---
 
 SOURCE_PRIORITY = {
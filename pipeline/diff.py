@@ -57,7 +57,7 @@ def generate_diff_report(
         cur_jurisdictions = pd.read_sql("SELECT * FROM jurisdictions", conn)
         cur_rates = pd.read_sql(
             "SELECT fips_code, rate, rate_type, source FROM rates "
-            "WHERE expiry_date IS NULL AND rate_type = 'general'",
+            "WHERE expiry_date IS NULL",
             conn,
         )
         cur_zips = pd.read_sql(
@@ -112,7 +112,7 @@ def generate_diff_report(
     _write("-" * 72)
 
     if not new_rates.empty and not cur_rates.empty:
-        merged = new_rates.merge(cur_rates, on="fips_code", suffixes=("_new", "_old"))
+        merged = new_rates.merge(cur_rates, on=["fips_code", "rate_type"], suffixes=("_new", "_old"))
         merged["delta"] = merged["rate_new"] - merged["rate_old"]
         changed = merged[merged["delta"].abs() > 1e-6]
 
@@ -129,7 +129,7 @@ def generate_diff_report(
             _write("  Rate increases (first 20):")
             for _, row in increases.head(20).iterrows():
                 _write(
-                    f"    ↑ {row['fips_code']:20s}  "
+                    f"    ↑ {row['fips_code']:20s}  {row['rate_type']:14s}  "
                     f"{row['rate_old']:.5f} → {row['rate_new']:.5f}  "
                     f"(+{row['delta']:.5f})"
                 )
@@ -141,7 +141,7 @@ def generate_diff_report(
             _write("  Rate decreases (first 20):")
             for _, row in decreases.head(20).iterrows():
                 _write(
-                    f"    ↓ {row['fips_code']:20s}  "
+                    f"    ↓ {row['fips_code']:20s}  {row['rate_type']:14s}  "
                     f"{row['rate_old']:.5f} → {row['rate_new']:.5f}  "
                     f"({row['delta']:.5f})"
                 )
@@ -149,15 +149,19 @@ def generate_diff_report(
                 _write(f"    ... and {len(decreases) - 20} more")
             _write()
 
-        # New rates (FIPS codes not in production)
-        new_rate_fips = set(new_rates["fips_code"]) - set(cur_rates["fips_code"])
-        if new_rate_fips:
-            _write(f"  New rates (no previous entry): {len(new_rate_fips):,}")
-            new_only = new_rates[new_rates["fips_code"].isin(new_rate_fips)].head(10)
-            for _, row in new_only.iterrows():
-                _write(f"    + {row['fips_code']:20s}  {row['rate']:.5f}")
-            if len(new_rate_fips) > 10:
-                _write(f"    ... and {len(new_rate_fips) - 10} more")
+        # New rates (FIPS code and rate type pairs not in production)
+        cur_keys = set(zip(cur_rates["fips_code"], cur_rates["rate_type"]))
+        is_new = [
+            (f, t) not in cur_keys
+            for f, t in zip(new_rates["fips_code"], new_rates["rate_type"])
+        ]
+        new_only = new_rates[is_new]
+        if not new_only.empty:
+            _write(f"  New rates (no previous entry): {len(new_only):,}")
+            for _, row in new_only.head(10).iterrows():
+                _write(f"    + {row['fips_code']:20s}  {row['rate_type']:14s}  {row['rate']:.5f}")
+            if len(new_only) > 10:
+                _write(f"    ... and {len(new_only) - 10} more")
             _write()
     else:
         _write("  No rate comparison possible (empty dataset).")
@@ -11,13 +11,13 @@ The pipeline NEVER writes directly to production tables.
 from __future__ import annotations
 
 import logging
-from datetime import date
+from datetime import date, datetime, timezone
 
 import pandas as pd
 import psycopg2
 from psycopg2.extras import execute_values
 
-from config import DATABASE_URL, pipeline_run_id
+from config import ADMINISTRATION_FILE, DATABASE_URL, pipeline_run_id
 
 logger = logging.getLogger(__name__)
 
@@ -26,9 +26,12 @@ def load_staging(
     jurisdictions: pd.DataFrame,
     rates: pd.DataFrame,
     zip_to_jurisdictions: pd.DataFrame,
+    zip4_ranges: pd.DataFrame | None = None,
 ) -> None:
     """Truncate staging tables and insert the new data.
 
+    ``zip4_ranges`` is optional because only SST publishes ZIP+4 ranges.
+
     This is safe to run multiple times — it always starts fresh.
     """
     conn = psycopg2.connect(DATABASE_URL)
@@ -37,6 +40,7 @@ def load_staging(
     try:
         with conn.cursor() as cur:
             # Truncate staging tables.
+            cur.execute("TRUNCATE TABLE zip4_to_jurisdictions_staging")
             cur.execute("TRUNCATE TABLE zip_to_jurisdictions_staging")
             cur.execute("TRUNCATE TABLE rates_staging")
             cur.execute("TRUNCATE TABLE jurisdictions_staging")
@@ -54,6 +58,10 @@ def load_staging(
             if not zip_to_jurisdictions.empty:
                 _insert_zip_staging(cur, zip_to_jurisdictions)
 
+            # Insert ZIP+4 range mappings.
+            if zip4_ranges is not None and not zip4_ranges.empty:
+                _insert_zip4_staging(cur, zip4_ranges)
+
         conn.commit()
         logger.info("Staging load complete")
 
@@ -87,7 +95,13 @@ def promote() -> dict:
         "rates_inserted": 0,
         "zip_mappings_expired": 0,
         "zip_mappings_inserted": 0,
+        "zip4_mappings_expired": 0,
+        "zip4_mappings_inserted": 0,
+        "districts_located_by_zip4": 0,
+        "districts_unlocated_by_zip4": 0,
         "rate_history_entries": 0,
+        "administration_updated": 0,
+        "dataset_version": None,
     }
 
     try:
@@ -110,11 +124,12 @@ def promote() -> dict:
 
             # --- Rates: record changes, expire old, insert new ---
 
-            # Find rate changes and record in rate_history.
+            # Find rate changes (per FIPS code and rate type) and record in rate_history.
             cur.execute("""
-                INSERT INTO rate_history (fips_code, old_rate, new_rate, changed_date, source, pipeline_run_id)
+                INSERT INTO rate_history (fips_code, rate_type, old_rate, new_rate, changed_date, source, pipeline_run_id)
                 SELECT
                     s.fips_code,
+                    s.rate_type,
                     p.rate AS old_rate,
                     s.rate AS new_rate,
                     %s AS changed_date,
@@ -122,21 +137,20 @@ def promote() -> dict:
                     %s AS pipeline_run_id
                 FROM rates_staging s
                 JOIN rates p ON p.fips_code = s.fips_code
+                    AND p.rate_type = s.rate_type
                     AND p.expiry_date IS NULL
-                    AND p.rate_type = 'general'
-                WHERE s.rate_type = 'general'
-                    AND s.rate != p.rate
+                WHERE s.rate != p.rate
             """, (today, run_id))
             summary["rate_history_entries"] = cur.rowcount
             logger.info("Recorded %d rate changes in history", cur.rowcount)
 
-            # Expire current active rates that have a corresponding staging record.
+            # Expire current active rates that have a corresponding staging
+            # record of the same rate type.
             cur.execute("""
                 UPDATE rates
                 SET expiry_date = %s
                 WHERE expiry_date IS NULL
-                    AND rate_type = 'general'
-                    AND fips_code IN (SELECT fips_code FROM rates_staging WHERE rate_type = 'general')
+                    AND (fips_code, rate_type) IN (SELECT fips_code, rate_type FROM rates_staging)
             """, (today,))
             summary["rates_expired"] = cur.rowcount
             logger.info("Expired %d old rates", cur.rowcount)
@@ -176,6 +190,69 @@ def promote() -> dict:
             summary["zip_mappings_inserted"] = cur.rowcount
             logger.info("Inserted %d new ZIP mappings", cur.rowcount)
 
+            # --- ZIP+4 ranges: expire ranges for ZIPs that were reloaded, insert new ---
+            # Ranges are replaced per ZIP rather than per row because range
+            # boundaries can shift between quarters.
+            cur.execute("""
+                UPDATE zip4_to_jurisdictions
+                SET expiry_date = %s
+                WHERE expiry_date IS NULL
+                    AND zip_code IN (SELECT zip_code FROM zip4_to_jurisdictions_staging)
+            """, (today,))
+            summary["zip4_mappings_expired"] = cur.rowcount
+            logger.info("Expired %d old ZIP+4 mappings", cur.rowcount)
+
+            cur.execute("""
+                INSERT INTO zip4_to_jurisdictions (zip_code, plus4_low, plus4_high, fips_code, effective_date, expiry_date)
+                SELECT zip_code, plus4_low, plus4_high, fips_code, effective_date, expiry_date
+                FROM zip4_to_jurisdictions_staging
+                ON CONFLICT (zip_code, plus4_low, plus4_high, fips_code, effective_date) DO UPDATE SET
+                    expiry_date = EXCLUDED.expiry_date
+            """)
+            summary["zip4_mappings_inserted"] = cur.rowcount
+            logger.info("Inserted %d new ZIP+4 mappings", cur.rowcount)
+
+            # --- Special districts: mark those now located by ZIP+4 ranges ---
+            # so lookups stop attaching them to every address in the parent
+            # county. Districts with polygon boundaries are left alone.
+            cur.execute("""
+                UPDATE jurisdictions
+                SET boundary_type = 'zip4', updated_at = now()
+                WHERE type = 'special_district'
+                    AND boundary_type = 'parent'
+                    AND fips_code IN (
+                        SELECT fips_code FROM zip4_to_jurisdictions WHERE expiry_date IS NULL
+                    )
+            """)
+            summary["districts_located_by_zip4"] = cur.rowcount
+            logger.info("Marked %d special districts as ZIP+4 bounded", cur.rowcount)
+
+            # ...and return those whose ranges have all expired to parent
+            # fallback, so they aren't dropped from every lookup.
+            cur.execute("""
+                UPDATE jurisdictions
+                SET boundary_type = 'parent', updated_at = now()
+                WHERE type = 'special_district'
+                    AND boundary_type = 'zip4'
+                    AND fips_code NOT IN (
+                        SELECT fips_code FROM zip4_to_jurisdictions WHERE expiry_date IS NULL
+                    )
+            """)
+            summary["districts_unlocated_by_zip4"] = cur.rowcount
+            logger.info("Returned %d special districts to parent fallback", cur.rowcount)
+
+            # --- Administration metadata: apply the curated reference file ---
+            summary["administration_updated"] = _apply_administration(cur)
+
+            # --- Dataset version: servers switch cache namespaces on it ---
+            version = f"{run_id}-{datetime.now(timezone.utc):%H%M%S}"
+            cur.execute(
+                "INSERT INTO dataset_versions (version, pipeline_run_id) VALUES (%s, %s)",
+                (version, run_id),
+            )
+            summary["dataset_version"] = version
+            logger.info("Recorded dataset version %s", version)
+
         conn.commit()
         logger.info("Promotion complete: %s", summary)
 
@@ -274,3 +351,78 @@ def _insert_zip_staging(cur, df) -> None:
         page_size=1000,
     )
     logger.info("Inserted %d ZIP mappings into staging", len(values))
+
+
+def _insert_zip4_staging(cur, df) -> None:
+    """Bulk insert ZIP+4 range mappings into staging."""
+    values = [
+        (
+            row["zip_code"],
+            row["plus4_low"],
+            row["plus4_high"],
+            row["fips_code"],
+            row["effective_date"],
+            row.get("expiry_date"),
+        )
+        for _, row in df.iterrows()
+    ]
+    execute_values(
+        cur,
+        """
+        INSERT INTO zip4_to_jurisdictions_staging (zip_code, plus4_low, plus4_high, fips_code, effective_date, expiry_date)
+        VALUES %s
+        ON CONFLICT (zip_code, plus4_low, plus4_high, fips_code, effective_date) DO NOTHING
+        """,
+        values,
+        page_size=1000,
+    )
+    logger.info("Inserted %d ZIP+4 mappings into staging", len(values))
+
+
+# ---------------------------------------------------------------------------
+# Administration metadata
+# ---------------------------------------------------------------------------
+
+def _apply_administration(cur) -> int:
+    """Apply curated administration metadata to production jurisdictions.
+
+    Rate files don't say who collects a locality's tax, so self-collected
+    jurisdictions are listed by hand in ADMINISTRATION_FILE. Jurisdictions
+    not in the file keep whatever they have; remove a row and set the
+    column back manually to revert one.
+    """
+    if not ADMINISTRATION_FILE.exists():
+        logger.info("No administration reference file at %s, skipping", ADMINISTRATION_FILE)
+        return 0
+
+    df = pd.read_csv(ADMINISTRATION_FILE, dtype=str).fillna("")
+    if df.empty:
+        return 0
+
+    values = [
+        (
+            row["fips_code"].strip(),
+            row["administration"].strip() or "state_administered",
+            row["filing_authority"].strip() or None,
+            row["registration_url"].strip() or None,
+            row["registration_id"].strip() or None,
+        )
+        for _, row in df.iterrows()
+    ]
+    execute_values(
+        cur,
+        """
+        UPDATE jurisdictions AS j SET
+            administration = v.administration,
+            filing_authority = v.filing_authority,
+            registration_url = v.registration_url,
+            registration_id = v.registration_id,
+            updated_at = now()
+        FROM (VALUES %s) AS v (fips_code, administration, filing_authority, registration_url, registration_id)
+        WHERE j.fips_code = v.fips_code
+        """,
+        values,
+        page_size=len(values),  # one statement, so rowcount covers every row
+    )
+    logger.info("Applied administration metadata to %d jurisdictions", cur.rowcount)
+    return cur.rowcount
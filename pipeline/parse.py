@@ -10,7 +10,7 @@ from pathlib import Path
 
 import pandas as pd
 
-from config import SST_STATES, current_quarter
+from config import RATE_TYPES, SST_STATES, current_quarter
 
 # Set of state FIPS codes covered by SST (used to avoid Avalara overlap).
 _SST_STATE_FIPS = set(SST_STATES.values())
@@ -37,6 +37,10 @@ ZIP_JUNCTIONS_COLS = [
     "zip_code", "fips_code", "is_primary", "effective_date", "expiry_date",
 ]
 
+ZIP4_RANGES_COLS = [
+    "zip_code", "plus4_low", "plus4_high", "fips_code", "effective_date", "expiry_date",
+]
+
 
 def _empty_jurisdictions() -> pd.DataFrame:
     return pd.DataFrame(columns=JURISDICTIONS_COLS)
@@ -50,10 +54,34 @@ def _empty_zip_junctions() -> pd.DataFrame:
     return pd.DataFrame(columns=ZIP_JUNCTIONS_COLS)
 
 
+def _empty_zip4_ranges() -> pd.DataFrame:
+    return pd.DataFrame(columns=ZIP4_RANGES_COLS)
+
+
 # ---------------------------------------------------------------------------
 # SST parsers
 # ---------------------------------------------------------------------------
 
+# SST jurisdiction type labels → jurisdictions.type. Parishes are counties
+# and home-rule cities are cities; towns and townships are distinct because
+# they sit alongside a county rather than replacing it.
+JURISDICTION_TYPES = {
+    "state": "state",
+    "county": "county",
+    "parish": "county",
+    "borough": "borough",
+    "city": "city",
+    "home_rule_city": "city",
+    "town": "county_subdivision",
+    "county_subdivision": "county_subdivision",
+    "township": "township",
+    "tribal": "tribal_area",
+    "tribal_area": "tribal_area",
+    "special": "special_district",
+    "special_district": "special_district",
+}
+
+
 def _parse_sst_rate_file(path: Path, state_abbr: str) -> tuple[pd.DataFrame, pd.DataFrame]:
     """Parse an SST per-state rate CSV into jurisdictions + rates DataFrames.
 
@@ -85,10 +113,7 @@ def _parse_sst_rate_file(path: Path, state_abbr: str) -> tuple[pd.DataFrame, pd.
     jurisdictions = pd.DataFrame({
         "fips_code": df[fips_col].str.strip(),
         "name": df[name_col].str.strip(),
-        "type": df[type_col].str.strip().str.lower().replace({
-            "state": "state", "county": "county", "city": "city",
-            "special": "special_district", "special_district": "special_district",
-        }),
+        "type": df[type_col].str.strip().str.lower().str.replace(" ", "_").replace(JURISDICTION_TYPES),
         "state_fips": state_fips,
         "parent_fips": None,
         "effective_date": _EFFECTIVE_DATE,
@@ -102,20 +127,43 @@ def _parse_sst_rate_file(path: Path, state_abbr: str) -> tuple[pd.DataFrame, pd.
         "generalrate", "general_rate", "rate",
     ])
 
+    rate_cols = {}
     if rate_col:
-        rates = pd.DataFrame({
+        rate_cols["general"] = rate_col
+    # Some states also publish lodging, rental car, prepared food or use tax
+    # rates as extra columns (e.g. LodgingRate, Rental_Car_Rate).
+    for rate_type in RATE_TYPES[1:]:
+        col = _find_col(df, [rate_type.replace("_", "") + "rate", rate_type + "_rate"])
+        if col:
+            rate_cols[rate_type] = col
+
+    typed = []
+    for rate_type, col in rate_cols.items():
+        values = pd.to_numeric(df[col], errors="coerce")
+        if rate_type != "general":
+            # A blank typed rate means the general rate applies.
+            present = values.notna()
+            typed.append(pd.DataFrame({
+                "fips_code": df.loc[present, fips_col].str.strip(),
+                "rate": values[present],
+                "rate_type": rate_type,
+                "effective_date": _EFFECTIVE_DATE,
+                "expiry_date": None,
+                "source": "sst",
+            }))
+            continue
+        typed.append(pd.DataFrame({
             "fips_code": df[fips_col].str.strip(),
-            "rate": pd.to_numeric(df[rate_col], errors="coerce").fillna(0),
+            "rate": values.fillna(0),
             "rate_type": "general",
             "effective_date": _EFFECTIVE_DATE,
             "expiry_date": None,
             "source": "sst",
-        })
-    else:
-        rates = _empty_rates()
+        }))
+    rates = pd.concat(typed, ignore_index=True) if typed else _empty_rates()
 
     jurisdictions = jurisdictions.drop_duplicates(subset=["fips_code"])
-    rates = rates.drop_duplicates(subset=["fips_code"])
+    rates = rates.drop_duplicates(subset=["fips_code", "rate_type"])
     return jurisdictions, rates
 
 
@@ -155,6 +203,86 @@ def _parse_sst_boundary_file(path: Path, state_abbr: str) -> pd.DataFrame:
     return result.drop_duplicates(subset=["zip_code", "fips_code"])
 
 
+def _parse_sst_zip4_file(path: Path) -> pd.DataFrame:
+    """Parse the ZIP+4 ranges from an SST boundary CSV.
+
+    Boundary rows carry the +4 range either as separate columns
+    (ZipExtensionLow, ZipExtensionHigh) or as a 9-digit ZipCode, which is
+    treated as a single-extension range. Rows with only a 5-digit ZIP are
+    skipped here; _parse_sst_boundary_file covers them.
+    """
+    try:
+        df = pd.read_csv(path, dtype=str, encoding="utf-8-sig")
+    except Exception:
+        logger.warning("Could not read SST boundary file %s, skipping", path)
+        return _empty_zip4_ranges()
+
+    df.columns = df.columns.str.strip().str.lower().str.replace(" ", "_")
+
+    zip_col = _find_col(df, ["zipcode", "zip_code", "zip", "zipcodelow", "zip_code_low"])
+    fips_col = _find_col(df, [
+        "compositefips", "composite_fips", "fips",
+        "jurisdictionfips", "jurisdiction_fips",
+    ])
+    low_col = _find_col(df, ["zipextensionlow", "zip_extension_low", "plus4_low"])
+    high_col = _find_col(df, ["zipextensionhigh", "zip_extension_high", "plus4_high"])
+
+    if not all([zip_col, fips_col]):
+        return _empty_zip4_ranges()
+
+    digits = df[zip_col].fillna("").str.strip().str.replace("-", "", regex=False)
+    zip5 = digits.str[:5]
+
+    if low_col and high_col:
+        # Extensions may arrive as integers ("1" for "0001"); blanks stay blank
+        # so 5-digit-only rows are filtered out below.
+        low = df[low_col].fillna("").str.strip()
+        high = df[high_col].fillna("").str.strip()
+        low = low.where(low == "", low.str.zfill(4))
+        high = high.where(high == "", high.str.zfill(4))
+    else:
+        low = digits.str[5:9]
+        high = low
+
+    result = pd.DataFrame({
+        "zip_code": zip5,
+        "plus4_low": low,
+        "plus4_high": high,
+        "fips_code": df[fips_col].str.strip(),
+        "effective_date": _EFFECTIVE_DATE,
+        "expiry_date": None,
+    })
+
+    valid = (
+        (result["zip_code"].str.len() == 5)
+        & result["plus4_low"].str.fullmatch(r"\d{4}")
+        & result["plus4_high"].str.fullmatch(r"\d{4}")
+        & (result["plus4_low"] <= result["plus4_high"])
+    )
+    result = result[valid]
+
+    return result.drop_duplicates(subset=["zip_code", "plus4_low", "plus4_high", "fips_code"])
+
+
+def parse_sst_zip4(files: list[Path]) -> pd.DataFrame:
+    """Parse ZIP+4 ranges from all downloaded SST boundary files.
+
+    Kept separate from parse_sst so the (jurisdictions, rates, zips) tuple
+    shared by every source stays unchanged; only SST publishes +4 ranges.
+    """
+    ranges = [
+        _parse_sst_zip4_file(path)
+        for path in files
+        if "_boundar" in path.stem.lower()
+    ]
+    if not ranges:
+        return _empty_zip4_ranges()
+
+    result = pd.concat(ranges, ignore_index=True)
+    logger.info("Parsed %d ZIP+4 range mappings", len(result))
+    return result
+
+
 def parse_sst(files: list[Path]) -> tuple[pd.DataFrame, pd.DataFrame, pd.DataFrame]:
     """Parse all downloaded SST files.
 
@@ -346,7 +474,7 @@ def parse_avalara(files: list[Path]) -> tuple[pd.DataFrame, pd.DataFrame, pd.Dat
 
     # Deduplicate.
     jurisdictions_df = jurisdictions_df.drop_duplicates(subset=["fips_code"], keep="first")
-    rates_df = rates_df.drop_duplicates(subset=["fips_code"], keep="first")
+    rates_df = rates_df.drop_duplicates(subset=["fips_code", "rate_type"], keep="first")
     zips_df = zips_df.drop_duplicates(subset=["zip_code", "fips_code"], keep="first")
 
     return jurisdictions_df, rates_df, zips_df
@@ -497,7 +625,7 @@ def parse_state_gov(files: list[Path]) -> tuple[pd.DataFrame, pd.DataFrame, pd.D
     zips_df = pd.concat(all_zips, ignore_index=True) if all_zips else _empty_zip_junctions()
 
     jurisdictions_df = jurisdictions_df.drop_duplicates(subset=["fips_code"], keep="first")
-    rates_df = rates_df.drop_duplicates(subset=["fips_code"], keep="first")
+    rates_df = rates_df.drop_duplicates(subset=["fips_code", "rate_type"], keep="first")
     zips_df = zips_df.drop_duplicates(subset=["zip_code", "fips_code"], keep="first")
 
     return jurisdictions_df, rates_df, zips_df
@@ -578,11 +706,11 @@ def merge_sources(
     else:
         merged_j = _empty_jurisdictions()
 
-    # Rates: highest priority per fips_code wins.
+    # Rates: highest priority per fips_code and rate_type wins.
     if all_r:
         merged_r = pd.concat(all_r, ignore_index=True)
         merged_r = merged_r.sort_values("_priority", ascending=False)
-        merged_r = merged_r.drop_duplicates(subset=["fips_code"], keep="first")
+        merged_r = merged_r.drop_duplicates(subset=["fips_code", "rate_type"], keep="first")
         merged_r = merged_r.drop(columns=["_priority"])
     else:
         merged_r = _empty_rates()
@@ -97,7 +97,7 @@ def _run_parse() -> None:
     logger.info("STAGE: PARSE")
     logger.info("=" * 60)
 
-    from parse import merge_sources, parse_avalara, parse_sst, parse_state_gov
+    from parse import merge_sources, parse_avalara, parse_sst, parse_sst_zip4, parse_state_gov
 
     downloaded = _state.get("downloaded_files")
     if not downloaded:
@@ -129,6 +129,7 @@ def _run_parse() -> None:
     if sst_files:
         logger.info("Parsing %d SST files", len(sst_files))
         sources["sst"] = parse_sst(sst_files)
+        _state["zip4_ranges"] = parse_sst_zip4(sst_files)
 
     avalara_files = downloaded.get("avalara", [])
     if avalara_files:
@@ -214,7 +215,7 @@ def _run_load() -> None:
         logger.error("No parsed data in state — did you run the parse stage first?")
         sys.exit(1)
 
-    load_staging(jurisdictions, rates, zips)
+    load_staging(jurisdictions, rates, zips, _state.get("zip4_ranges"))
     logger.info("Data loaded into staging tables. Review the diff report before promoting.")
 
 
@@ -238,7 +239,7 @@ def _run_promote(confirm: bool) -> None:
     logger.info("Promotion summary: %s", summary)
     print("\nPromotion complete:")
     for key, value in summary.items():
-        print(f"  {key}: {value:,}")
+        print(f"  {key}: {value:,}" if isinstance(value, int) else f"  {key}: {value}")
 
 
 if __name__ == "__main__":
@@ -4,6 +4,7 @@ Checks:
   - Every rate is within [0%, 15%].
   - ZIP coverage meets the minimum threshold (~41k US ZIPs).
   - No jurisdiction has a NULL or negative rate.
+  - Every rate_type is one the rates table accepts.
   - Quarter-over-quarter rate changes > 2 percentage points are flagged.
 """
 
@@ -19,6 +20,7 @@ from config import (
     MAX_RATE_DELTA,
     MIN_RATE,
     MIN_ZIP_COVERAGE,
+    RATE_TYPES,
 )
 
 logger = logging.getLogger(__name__)
@@ -65,6 +67,7 @@ def validate(
     _check_empty(jurisdictions, rates, zip_to_jurisdictions, result)
     _check_rate_bounds(rates, result)
     _check_null_rates(rates, result)
+    _check_rate_types(rates, result)
     _check_zip_coverage(zip_to_jurisdictions, result)
     _check_orphan_rates(jurisdictions, rates, result)
     _check_orphan_zip_mappings(jurisdictions, zip_to_jurisdictions, result)
@@ -129,6 +132,19 @@ def _check_null_rates(rates: pd.DataFrame, result: ValidationResult) -> None:
         )
 
 
+def _check_rate_types(rates: pd.DataFrame, result: ValidationResult) -> None:
+    """Every rate_type must be one the rates table's CHECK constraint accepts."""
+    if rates.empty:
+        return
+
+    unknown = rates[~rates["rate_type"].isin(RATE_TYPES)]
+    if not unknown.empty:
+        result.errors.append(
+            f"{len(unknown)} rates have unknown rate types: "
+            f"{sorted(unknown['rate_type'].astype(str).unique().tolist())[:5]}"
+        )
+
+
 def _check_zip_coverage(zips: pd.DataFrame, result: ValidationResult) -> None:
     """We should cover at least MIN_ZIP_COVERAGE unique 5-digit ZIP codes."""
     if zips.empty:
@@ -204,7 +220,7 @@ def _check_rate_deltas(rates: pd.DataFrame, result: ValidationResult) -> None:
 
     try:
         current = pd.read_sql(
-            "SELECT fips_code, rate FROM rates WHERE expiry_date IS NULL AND rate_type = 'general'",
+            "SELECT fips_code, rate_type, rate FROM rates WHERE expiry_date IS NULL",
             conn,
         )
     except Exception as exc:
@@ -217,12 +233,12 @@ def _check_rate_deltas(rates: pd.DataFrame, result: ValidationResult) -> None:
         logger.info("No existing rates in production — delta check skipped (first load).")
         return
 
-    merged = rates.merge(current, on="fips_code", suffixes=("_new", "_old"))
+    merged = rates.merge(current, on=["fips_code", "rate_type"], suffixes=("_new", "_old"))
     merged["delta"] = abs(merged["rate_new"] - merged["rate_old"])
     big_changes = merged[merged["delta"] > MAX_RATE_DELTA]
 
     if not big_changes.empty:
-        samples = big_changes[["fips_code", "rate_old", "rate_new", "delta"]].head(10)
+        samples = big_changes[["fips_code", "rate_type", "rate_old", "rate_new", "delta"]].head(10)
         result.warnings.append(
             f"{len(big_changes)} jurisdictions have rate changes > {MAX_RATE_DELTA:.1%}:\n"
             f"{samples.to_string(index=False)}"